@@ -17,6 +17,8 @@ const (
 	ErrNoSelection       = "no selection, exiting"
 	ErrInvalidNamespace  = "first argument must be a valid k8s namespace"
 	ErrAutocompleteError = "autocomplete file walk errored"
+	ErrNoExperimentPath  = "first argument must be a path to experiment file"
+	ErrFormatWithDiff    = "--format can't be used with --diff or --merge, they only work with the experiments dir"
 )
 
 func experimentCompleter(dir string, expType string) (func(d prompt.Document) []prompt.Suggest, error) {
@@ -68,6 +70,37 @@ func experimentTypeCompleter(dir string) (func(d prompt.Document) []prompt.Sugge
 	}, nil
 }
 
+// chooseNamespaceDir asks to choose a namespace if experiments were generated for multiple namespaces
+func chooseNamespaceDir(dir string) (string, error) {
+	if err := readableExperimentsDir(dir); err != nil {
+		return "", err
+	}
+	dirs, err := experimentDirs(dir)
+	if err != nil {
+		return "", err
+	}
+	if len(dirs) <= 1 {
+		return dir, nil
+	}
+	s := make([]prompt.Suggest, 0)
+	dirsByName := make(map[string]string)
+	for _, d := range dirs {
+		dirsByName[filepath.Base(d)] = d
+		s = append(s, prompt.Suggest{
+			Text:        filepath.Base(d),
+			Description: d,
+		})
+	}
+	ns := prompt.Input("Choose namespace >> ", func(d prompt.Document) []prompt.Suggest {
+		return prompt.FilterHasPrefix(s, d.GetWordBeforeCursor(), true)
+	})
+	nsDir, ok := dirsByName[ns]
+	if !ok {
+		return "", errors.New(ErrNoSelection)
+	}
+	return nsDir, nil
+}
+
 func RunCLI(args []string) error {
 	app := &cli.App{
 		EnableBashCompletion: true,
@@ -93,11 +126,25 @@ or use custom config
 havoc -c havoc.toml generate [namespace]
 you can also specify a directory where to put manifests
 havoc -c havoc.toml -d custom_experiments [namespace]
+for multiple namespaces experiments are generated into a subdirectory per namespace
+havoc -c havoc.toml generate [namespace] [namespace]
+namespaces can also be set in config with "namespaces" or "namespace_selector"
+havoc -c havoc.toml generate
+show what would change in the existing experiments dir without writing anything
+havoc -c havoc.toml generate --diff [namespace]
+update the existing experiments dir, keeping files marked with "havoc.io/hand-maintained"
+havoc -c havoc.toml generate --merge [namespace]
+write experiments as a multi-document YAML, a Kustomize base, a Helm chart or with a JSON index
+havoc -c havoc.toml generate --format helm [namespace]
 `,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{Name: "diff", Usage: "print added, removed and changed experiments compared to the experiments dir without writing anything"},
+					&cli.BoolFlag{Name: "merge", Usage: "merge experiments into the experiments dir instead of rewriting it, hand-maintained files are preserved"},
+					&cli.StringFlag{Name: "format", Value: OutputFormatDir, Usage: "output format: dir, yaml, kustomize, helm or json"},
+				},
 				Action: func(cliCtx *cli.Context) error {
-					ns := cliCtx.Args().Get(0)
-					if ns == "" {
-						return errors.New(ErrInvalidNamespace)
+					if (cliCtx.Bool("diff") || cliCtx.Bool("merge")) && cliCtx.String("format") != OutputFormatDir {
+						return errors.New(ErrFormatWithDiff)
 					}
 					cfg, err := ReadConfig(cliCtx.String("config"))
 					if err != nil {
@@ -107,7 +154,29 @@ havoc -c havoc.toml -d custom_experiments [namespace]
 					if err != nil {
 						return err
 					}
-					return m.GenerateSpecs(ns)
+					namespaces, err := m.ResolveNamespaces(cliCtx.Args().Slice())
+					if err != nil {
+						return errors.Wrap(err, ErrInvalidNamespace)
+					}
+					switch {
+					case cliCtx.Bool("diff"):
+						d, err := m.DiffSpecsForNamespaces(namespaces)
+						if err != nil {
+							return err
+						}
+						fmt.Print(d.String())
+						return nil
+					case cliCtx.Bool("merge"):
+						d, err := m.MergeSpecsForNamespaces(namespaces)
+						if err != nil {
+							return err
+						}
+						fmt.Print(d.String())
+						return nil
+					case cliCtx.String("format") != OutputFormatDir:
+						return m.ExportSpecsForNamespaces(namespaces, cliCtx.String("format"))
+					}
+					return m.GenerateSpecsForNamespaces(namespaces)
 				},
 			},
 			{
@@ -120,7 +189,12 @@ examples:
 havoc -c havoc.toml apply
 # applying experiment directly with relative or abs path
 havoc apply ${experiment_path}
+# validating experiment and printing pods it would affect, without injecting anything
+havoc apply --dry-run ${experiment_path}
 `,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{Name: "dry-run", Usage: "validate experiment with server-side dry-run and print a plan without injecting anything"},
+				},
 				Action: func(cliCtx *cli.Context) error {
 					cfg, err := ReadConfig(cliCtx.String("config"))
 					if err != nil {
@@ -130,11 +204,6 @@ havoc apply ${experiment_path}
 					if err != nil {
 						return err
 					}
-					cc, err := experimentTypeCompleter(m.cfg.Havoc.Dir)
-					if err != nil {
-						return err
-					}
-
 					var expPath string
 
 					arg := cliCtx.Args().Get(0)
@@ -142,11 +211,19 @@ havoc apply ${experiment_path}
 					if arg != "" {
 						expPath = arg
 					} else {
+						dir, err := chooseNamespaceDir(m.cfg.Havoc.Dir)
+						if err != nil {
+							return err
+						}
+						cc, err := experimentTypeCompleter(dir)
+						if err != nil {
+							return err
+						}
 						expType := prompt.Input("Choose experiment type >> ", cc)
 						if expType == "" {
 							return errors.New(ErrNoSelection)
 						}
-						c, err := experimentCompleter(m.cfg.Havoc.Dir, expType)
+						c, err := experimentCompleter(dir, expType)
 						if err != nil {
 							return errors.Wrap(err, ErrAutocompleteError)
 						}
@@ -154,7 +231,7 @@ havoc apply ${experiment_path}
 						if expName == "" {
 							return errors.New(ErrNoSelection)
 						}
-						expPath = fmt.Sprintf("%s/%s/%s", cfg.Havoc.Dir, expType, expName)
+						expPath = fmt.Sprintf("%s/%s/%s", dir, expType, expName)
 					}
 
 					nexp, err := NewNamedExperiment(expPath)
@@ -162,9 +239,71 @@ havoc apply ${experiment_path}
 						return err
 					}
 
+					if cliCtx.Bool("dry-run") {
+						p, err := m.DryRunApply(nexp)
+						if err != nil {
+							return err
+						}
+						fmt.Print(p.String())
+						return nil
+					}
 					return m.ApplyAndAnnotate(nexp)
 				},
 			},
+			{
+				Name:     "targets",
+				HelpName: "targets",
+				Aliases:  []string{"t"},
+				Description: `shows pods experiment selectors could affect and how many of them would be picked:
+examples:
+# evaluating selectors against pods running in the cluster
+havoc targets ${experiment_path}
+# evaluating selectors against pods list dumped with "kubectl get pods -o json"
+havoc targets --pods pods.json ${experiment_path}
+`,
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "pods", Usage: "path to PodsListResponse JSON file, live pods list is used if empty"},
+				},
+				Action: func(cliCtx *cli.Context) error {
+					expPath := cliCtx.Args().Get(0)
+					if expPath == "" {
+						return errors.New(ErrNoExperimentPath)
+					}
+					cfg, err := ReadConfig(cliCtx.String("config"))
+					if err != nil {
+						return err
+					}
+					m, err := NewController(cfg)
+					if err != nil {
+						return err
+					}
+					nexp, err := NewNamedExperiment(expPath)
+					if err != nil {
+						return err
+					}
+					var selected, target *SelectedTargets
+					if podsPath := cliCtx.String("pods"); podsPath != "" {
+						plr, err := ReadPodsListResponse(podsPath)
+						if err != nil {
+							return err
+						}
+						selected, target, err = ExperimentTargets(nexp, plr)
+						if err != nil {
+							return err
+						}
+					} else {
+						selected, target, err = m.LiveExperimentTargets(nexp)
+						if err != nil {
+							return err
+						}
+					}
+					fmt.Printf("Selector\n%s", selected)
+					if target != nil {
+						fmt.Printf("Target\n%s", target)
+					}
+					return nil
+				},
+			},
 			{
 				Name:     "run",
 				HelpName: "run",
@@ -172,9 +311,15 @@ havoc apply ${experiment_path}
 				Description: `starts a chaos monkey
 examples:
 havoc run -c havoc.toml [namespace]
+# monkey picks experiments from all namespaces
+havoc run -c havoc.toml [namespace] [namespace]
+# validating all experiments and printing monkey plan, without injecting anything
+havoc run -c havoc.toml --dry-run [namespace]
 `,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{Name: "dry-run", Usage: "validate experiments with server-side dry-run and print monkey plan without injecting anything"},
+				},
 				Action: func(cliCtx *cli.Context) error {
-					ns := cliCtx.Args().Get(0)
 					cfgPath := cliCtx.String("config")
 					cfg, err := ReadConfig(cfgPath)
 					if err != nil {
@@ -184,22 +329,42 @@ havoc run -c havoc.toml [namespace]
 					if err != nil {
 						return err
 					}
+					dryRun := cliCtx.Bool("dry-run")
 					if _, err := os.Stat(cfg.Havoc.Dir); err != nil {
+						namespaces, err := m.ResolveNamespaces(cliCtx.Args().Slice())
+						if err != nil {
+							return errors.Wrap(err, ErrInvalidNamespace)
+						}
+						if dryRun {
+							L.Info().
+								Str("Dir", cfg.Havoc.Dir).
+								Msg("Dir not found, planning experiments generated in memory")
+							p, err := m.DryRunNamespaces(namespaces)
+							if err != nil {
+								return err
+							}
+							fmt.Print(p.String())
+							return nil
+						}
 						L.Info().
 							Str("Dir", cfg.Havoc.Dir).
 							Msg("Dir not found, generating specified experiments directory")
-						err = m.GenerateSpecs(ns)
-						if err != nil {
+						if err := m.GenerateSpecsForNamespaces(namespaces); err != nil {
 							return err
 						}
-						L.Info().
-							Str("Dir", cfg.Havoc.Dir).
-							Msg("Using existing experiments dir, skipping generation")
 					} else {
 						L.Info().
 							Str("Dir", cfg.Havoc.Dir).
 							Msg("Using existing experiments dir, skipping generation")
 					}
+					if dryRun {
+						p, err := m.DryRun()
+						if err != nil {
+							return err
+						}
+						fmt.Print(p.String())
+						return nil
+					}
 					return m.Run()
 				},
 			},
@@ -4,6 +4,7 @@ import (
 	"github.com/pelletier/go-toml/v2"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
+	"github.com/samber/lo"
 	"os"
 	"strings"
 )
@@ -12,31 +13,36 @@ const (
 	ErrReadSethConfig      = "failed to read TOML config for havoc"
 	ErrUnmarshalSethConfig = "failed to unmarshal TOML config for havoc"
 
-	ErrFailureGroupIsNil      = "failure group must be specified in config"
-	ErrLatencyGroupIsNil      = "latency group must be specified in config"
-	ErrStressCPUGroupIsNil    = "stress cpu group must be specified in config"
-	ErrStressMemoryGroupIsNil = "stress memory group must be specified in config"
-	ErrFormat                 = "format error"
+	ErrFailureGroupIsNil       = "failure group must be specified in config"
+	ErrLatencyGroupIsNil       = "latency group must be specified in config"
+	ErrStressCPUGroupIsNil     = "stress cpu group must be specified in config"
+	ErrStressMemoryGroupIsNil  = "stress memory group must be specified in config"
+	ErrContainerKillGroupIsNil = "container kill group must be specified in config"
+	ErrTopologyGroupIsNil      = "topology group must be specified in config to generate node or zone experiments"
+	ErrFormat                  = "format error"
 )
 
 const (
-	DefaultExperimentsDir           = "havoc-experiments"
-	DefaultPodFailureDuration       = "1m"
-	DefaultNetworkLatencyDuration   = "1m"
-	DefaultNetworkPartitionDuration = "1m"
-	DefaultHTTPDuration             = "1m"
-	DefaultNetworkPartitionLabel    = "havoc-network-group"
-	DefaultComponentGroupLabelKey   = "havoc-component-group"
-	DefaultStressMemoryDuration     = "1m"
-	DefaultStressMemoryWorkers      = 1
-	DefaultStressMemoryAmount       = "512MB"
-	DefaultStressCPUDuration        = "1m"
-	DefaultStressCPUWorkers         = 1
-	DefaultStressCPULoad            = 100
-	DefaultNetworkLatency           = "300ms"
-	DefaultMonkeyDuration           = "24h"
-	DefaultMonkeyMode               = "seq"
-	DefaultMonkeyCooldown           = "30s"
+	DefaultExperimentsDir            = "havoc-experiments"
+	DefaultPodFailureDuration        = "1m"
+	DefaultNetworkLatencyDuration    = "1m"
+	DefaultNetworkPartitionDuration  = "1m"
+	DefaultHTTPDuration              = "1m"
+	DefaultNetworkPartitionLabel     = "havoc-network-group"
+	DefaultNetworkPartitionDirection = "from"
+	DefaultComponentGroupLabelKey    = "havoc-component-group"
+	DefaultStressMemoryDuration      = "1m"
+	DefaultStressMemoryWorkers       = 1
+	DefaultStressMemoryAmount        = "512MB"
+	DefaultStressCPUDuration         = "1m"
+	DefaultStressCPUWorkers          = 1
+	DefaultStressCPULoad             = 100
+	DefaultNetworkLatency            = "300ms"
+	DefaultMonkeyDuration            = "24h"
+	DefaultMonkeyMode                = "seq"
+	DefaultMonkeyCooldown            = "30s"
+	DefaultTopologyDuration          = "1m"
+	DefaultTopologyZoneLabel         = "topology.kubernetes.io/zone"
 )
 
 var (
@@ -72,22 +78,32 @@ type Config struct {
 }
 
 type Havoc struct {
-	Dir                  string                `toml:"dir"`
-	ExperimentTypes      []string              `toml:"experiment_types"`
-	NamespaceLabelFilter string                `toml:"namespace_label_filter"`
-	ComponentLabelKey    string                `toml:"component_label_key"`
-	IgnoredPods          []string              `toml:"ignore_pods"`
-	IgnoreGroupLabels    []string              `toml:"ignore_group_labels"`
-	Failure              *Failure              `toml:"failure"`
-	Latency              *Latency              `toml:"latency"`
-	NetworkPartition     *NetworkPartition     `toml:"network_partition"`
-	StressMemory         *StressMemory         `toml:"stress_memory"`
-	StressCPU            *StressCPU            `toml:"stress_cpu"`
-	ExternalTargets      *ExternalTargets      `toml:"external_targets"`
-	BlockchainRewindHead *BlockchainRewindHead `toml:"blockchain_rewind_head"`
-	OpenAPI              *OpenAPI              `toml:"openapi"`
-	Monkey               *Monkey               `toml:"monkey"`
-	Grafana              *Grafana              `toml:"grafana"`
+	Dir string `toml:"dir"`
+	// TemplatesDir has user templates overriding default experiment templates, see TemplateDataTypes
+	TemplatesDir         string   `toml:"templates_dir"`
+	ExperimentTypes      []string `toml:"experiment_types"`
+	Namespaces           []string `toml:"namespaces"`
+	NamespaceSelector    string   `toml:"namespace_selector"`
+	NamespaceLabelFilter string   `toml:"namespace_label_filter"`
+	ComponentLabelKey    string   `toml:"component_label_key"`
+	// ComponentLabelKeys groups pods by a combination of labels, overrides ComponentLabelKey
+	ComponentLabelKeys        []string              `toml:"component_label_keys"`
+	ComponentLabelExpressions []*LabelExpression    `toml:"component_label_expressions"`
+	WorkloadDiscovery         bool                  `toml:"workload_discovery"`
+	IgnoredPods               []string              `toml:"ignore_pods"`
+	IgnoreGroupLabels         []string              `toml:"ignore_group_labels"`
+	Failure                   *Failure              `toml:"failure"`
+	Latency                   *Latency              `toml:"latency"`
+	NetworkPartition          *NetworkPartition     `toml:"network_partition"`
+	StressMemory              *StressMemory         `toml:"stress_memory"`
+	StressCPU                 *StressCPU            `toml:"stress_cpu"`
+	ContainerKill             *ContainerKill        `toml:"container_kill"`
+	ExternalTargets           *ExternalTargets      `toml:"external_targets"`
+	BlockchainRewindHead      *BlockchainRewindHead `toml:"blockchain_rewind_head"`
+	Topology                  *Topology             `toml:"topology"`
+	OpenAPI                   *OpenAPI              `toml:"openapi"`
+	Monkey                    *Monkey               `toml:"monkey"`
+	Grafana                   *Grafana              `toml:"grafana"`
 }
 
 func dumpConfig(cfg *Config) {
@@ -97,42 +113,51 @@ func dumpConfig(cfg *Config) {
 	}
 }
 
+// DefaultConfig returns a new default config, slices are copied, so ReadConfig can't modify package defaults
 func DefaultConfig() *Config {
 	return &Config{
 		Havoc: &Havoc{
 			Dir:               DefaultExperimentsDir,
-			ExperimentTypes:   RecommendedExperimentTypes,
+			ExperimentTypes:   append([]string{}, RecommendedExperimentTypes...),
 			ComponentLabelKey: DefaultComponentGroupLabelKey,
-			IgnoreGroupLabels: DefaultIgnoreGroupLabels,
+			IgnoreGroupLabels: append([]string{}, DefaultIgnoreGroupLabels...),
 			Failure: &Failure{
 				Duration:   DefaultPodFailureDuration,
-				GroupFixed: DefaultGroupFixed,
+				GroupFixed: append([]string{}, DefaultGroupFixed...),
 			},
 			Latency: &Latency{
 				Duration:   DefaultNetworkLatencyDuration,
 				Latency:    DefaultNetworkLatency,
-				GroupFixed: DefaultGroupFixed,
+				GroupFixed: append([]string{}, DefaultGroupFixed...),
 			},
 			StressMemory: &StressMemory{
 				Duration:   DefaultStressMemoryDuration,
 				Workers:    DefaultStressMemoryWorkers,
 				Memory:     DefaultStressMemoryAmount,
-				GroupFixed: DefaultGroupFixed,
+				GroupFixed: append([]string{}, DefaultGroupFixed...),
 			},
 			StressCPU: &StressCPU{
 				Duration:   DefaultStressCPUDuration,
 				Workers:    DefaultStressCPUWorkers,
 				Load:       DefaultStressCPULoad,
-				GroupFixed: DefaultGroupFixed,
+				GroupFixed: append([]string{}, DefaultGroupFixed...),
 			},
 			NetworkPartition: &NetworkPartition{
 				Duration:        DefaultNetworkPartitionDuration,
 				Label:           DefaultNetworkPartitionLabel,
-				GroupPercentage: DefaultNetworkPartitionGroupPercentage,
+				GroupPercentage: append([]string{}, DefaultNetworkPartitionGroupPercentage...),
+				Direction:       DefaultNetworkPartitionDirection,
+			},
+			ContainerKill: &ContainerKill{
+				GroupFixed: append([]string{}, DefaultGroupFixed...),
+			},
+			Topology: &Topology{
+				Duration:  DefaultTopologyDuration,
+				ZoneLabel: DefaultTopologyZoneLabel,
 			},
 			OpenAPI: &OpenAPI{
 				Duration:   DefaultHTTPDuration,
-				GroupFixed: DefaultGroupFixed,
+				GroupFixed: append([]string{}, DefaultGroupFixed...),
 			},
 			Monkey: &Monkey{
 				Duration: DefaultMonkeyDuration,
@@ -193,6 +218,30 @@ func (c *Config) Validate() []error {
 			errs = append(errs, errors.Wrap(errors.New(ErrFormat), "stress_cpu.load must be set, ex.: \"100\""))
 		}
 	}
+	for _, e := range c.Havoc.ComponentLabelExpressions {
+		if err := e.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if c.Havoc.NetworkPartition != nil {
+		d := c.Havoc.NetworkPartition.Direction
+		if d != "" && d != PartitionDirectionTo && d != PartitionDirectionFrom && d != PartitionDirectionBoth {
+			errs = append(errs, errors.Wrap(errors.New(ErrFormat), "network_partition.direction must be either \"to\", \"from\" or \"both\""))
+		}
+	}
+	if len(lo.Intersect(c.Havoc.ExperimentTypes, TopologyExperimentTypes)) > 0 && c.Havoc.Topology == nil {
+		errs = append(errs, errors.New(ErrTopologyGroupIsNil))
+	}
+	if c.Havoc.Topology != nil {
+		if c.Havoc.Topology.Duration == "" {
+			errs = append(errs, errors.Wrap(errors.New(ErrFormat), "topology.duration must be in Go duration format, 1d2h3m0s"))
+		}
+	}
+	if sliceContains(ChaosTypeGroupContainerKill, c.Havoc.ExperimentTypes) || sliceContains(ChaosTypeContainerKill, c.Havoc.ExperimentTypes) {
+		if c.Havoc.ContainerKill == nil {
+			errs = append(errs, errors.New(ErrContainerKillGroupIsNil))
+		}
+	}
 	if c.Havoc.BlockchainRewindHead != nil {
 		if c.Havoc.BlockchainRewindHead.Duration == "" {
 			errs = append(errs, errors.Wrap(errors.New(ErrFormat), "havoc.blockchain_rewind_head.duration must be set, ex.: \"30s\""))
@@ -241,22 +290,44 @@ type NetworkPartition struct {
 	Label           string   `toml:"label"`
 	GroupPercentage []string `toml:"group_percentage"`
 	GroupFixed      []string `toml:"group_fixed"`
+	// Direction is ChaosMesh partition direction: "to", "from" or "both"
+	Direction          string   `toml:"direction"`
+	Services           []string `toml:"services"`
+	Isolate            bool     `toml:"isolate"`
+	CrossNamespace     bool     `toml:"cross_namespace"`
+	NamespacePartition bool     `toml:"namespace_partition"`
+}
+
+type Topology struct {
+	Duration string `toml:"duration"`
+	// ZoneLabel is a node label pods are grouped by for zone experiments
+	ZoneLabel string `toml:"zone_label"`
+	// Zones are zones eligible for zone experiments, all zones are eligible if empty
+	Zones []string `toml:"zones"`
 }
 
 type StressMemory struct {
-	Duration        string   `toml:"duration"`
-	Workers         int      `toml:"workers"`
-	Memory          string   `toml:"memory"`
-	GroupPercentage []string `toml:"group_percentage"`
-	GroupFixed      []string `toml:"group_fixed"`
+	Duration        string           `toml:"duration"`
+	Workers         int              `toml:"workers"`
+	Memory          string           `toml:"memory"`
+	GroupPercentage []string         `toml:"group_percentage"`
+	GroupFixed      []string         `toml:"group_fixed"`
+	Containers      *ContainerFilter `toml:"containers"`
 }
 
 type StressCPU struct {
-	Duration        string   `toml:"duration"`
-	Workers         int      `toml:"workers"`
-	Load            int      `toml:"load"`
-	GroupPercentage []string `toml:"group_percentage"`
-	GroupFixed      []string `toml:"group_fixed"`
+	Duration        string           `toml:"duration"`
+	Workers         int              `toml:"workers"`
+	Load            int              `toml:"load"`
+	GroupPercentage []string         `toml:"group_percentage"`
+	GroupFixed      []string         `toml:"group_fixed"`
+	Containers      *ContainerFilter `toml:"containers"`
+}
+
+type ContainerKill struct {
+	GroupPercentage []string         `toml:"group_percentage"`
+	GroupFixed      []string         `toml:"group_fixed"`
+	Containers      *ContainerFilter `toml:"containers"`
 }
 
 type ExternalTargets struct {
@@ -290,6 +361,10 @@ type OpenApiSpecInfo struct {
 type SpecToPort struct {
 	Port int64  `toml:"port"`
 	Path string `toml:"path"`
+	// PortName is a container port name, resolved to Port from component group pods
+	PortName string `toml:"port_name"`
+	// Data is spec content when spec is read from a pod or a ConfigMap
+	Data []byte `toml:"-"`
 }
 
 type Monkey struct {
@@ -0,0 +1,91 @@
+package havoc
+
+import (
+	"path"
+	"sort"
+)
+
+// ContainerFilter selects containers by name patterns in path.Match format, ex.: "istio-*",
+// all containers are included if Include is empty, Exclude takes precedence
+type ContainerFilter struct {
+	Include []string `toml:"include"`
+	Exclude []string `toml:"exclude"`
+}
+
+// Configured checks if filter has any patterns, experiments target the whole pod otherwise
+func (f *ContainerFilter) Configured() bool {
+	return f != nil && (len(f.Include) > 0 || len(f.Exclude) > 0)
+}
+
+// Matches checks if container name passes the filter
+func (f *ContainerFilter) Matches(name string) bool {
+	if f == nil {
+		return true
+	}
+	for _, p := range f.Exclude {
+		if ok, _ := path.Match(p, name); ok {
+			return false
+		}
+	}
+	if len(f.Include) == 0 {
+		return true
+	}
+	for _, p := range f.Include {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// containerFilter returns container filter for experiment type, nil if type has no container filter
+func (m *Controller) containerFilter(expType string) *ContainerFilter {
+	switch expType {
+	case ChaosTypeStressCPU, ChaosTypeStressGroupCPU:
+		return m.cfg.Havoc.StressCPU.Containers
+	case ChaosTypeStressMemory, ChaosTypeStressGroupMemory:
+		return m.cfg.Havoc.StressMemory.Containers
+	case ChaosTypeContainerKill, ChaosTypeGroupContainerKill:
+		if m.cfg.Havoc.ContainerKill == nil {
+			return nil
+		}
+		return m.cfg.Havoc.ContainerKill.Containers
+	}
+	return nil
+}
+
+// podContainers returns sorted unique names of pods containers passing experiment type filter,
+// experiment should be skipped if filter is configured, or experiment requires containers, and nothing matched
+func (m *Controller) podContainers(expType string, pods ...*PodResponse) ([]string, bool) {
+	f := m.containerFilter(expType)
+	required := expType == ChaosTypeContainerKill || expType == ChaosTypeGroupContainerKill
+	if !f.Configured() && !required {
+		return nil, true
+	}
+	names := make([]string, 0)
+	for _, p := range pods {
+		for _, c := range p.Spec.Containers {
+			if f.Matches(c.Name) && !sliceContains(c.Name, names) {
+				names = append(names, c.Name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, len(names) > 0
+}
+
+// ContainerKillExperiment is template data of container-kill and group-container-kill experiments
+type ContainerKillExperiment struct {
+	ExperimentName      string
+	Mode                string
+	ModeValue           string
+	Namespace           string
+	PodName             string
+	Selector            string
+	ExpressionSelectors string
+	ContainerNames      []string
+}
+
+func (m ContainerKillExperiment) String() (string, error) {
+	return objectString(m)
+}
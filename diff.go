@@ -0,0 +1,256 @@
+package havoc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/samber/lo"
+)
+
+// HandMaintainedKey marks experiment files that are maintained by hand, put it in a comment line before the manifest,
+// ex.: "# havoc.io/hand-maintained", merge never overwrites or removes such files
+const HandMaintainedKey = "havoc.io/hand-maintained"
+
+// SpecsDiff is a difference between generated experiments and an existing experiments dir,
+// paths are relative to experiments dir
+type SpecsDiff struct {
+	Added   []string
+	Removed []string
+	Changed []string
+	// Kept are hand-maintained files that would be changed or removed otherwise
+	Kept []string
+	// Unified are unified diffs of changed files
+	Unified map[string]string
+}
+
+// Empty checks if generated experiments are the same as existing ones
+func (m *SpecsDiff) Empty() bool {
+	return len(m.Added) == 0 && len(m.Removed) == 0 && len(m.Changed) == 0
+}
+
+// String prints a summary of added, removed and changed files followed by unified diffs
+func (m *SpecsDiff) String() string {
+	var sb strings.Builder
+	for _, f := range m.Added {
+		sb.WriteString(fmt.Sprintf("+ %s\n", f))
+	}
+	for _, f := range m.Removed {
+		sb.WriteString(fmt.Sprintf("- %s\n", f))
+	}
+	for _, f := range m.Changed {
+		sb.WriteString(fmt.Sprintf("~ %s\n", f))
+	}
+	for _, f := range m.Kept {
+		sb.WriteString(fmt.Sprintf("= %s (hand-maintained)\n", f))
+	}
+	for _, f := range m.Changed {
+		sb.WriteString(m.Unified[f])
+	}
+	return sb.String()
+}
+
+// Files returns experiments by file path relative to experiments dir
+func (m *ChaosSpecs) Files() map[string]string {
+	files := make(map[string]string)
+	for expType, experiments := range m.ExperimentsByType {
+		for expName, expBody := range experiments {
+			files[strings.ToLower(fmt.Sprintf("%s/%s-%s.yaml", expType, expType, expName))] = expBody
+		}
+	}
+	return files
+}
+
+// namespaceFiles returns experiments of multiple namespaces by file path relative to experiments dir
+func namespaceFiles(allSpecs map[string]*ChaosSpecs) map[string]string {
+	files := make(map[string]string)
+	for ns, csp := range allSpecs {
+		for f, body := range csp.Files() {
+			files[fmt.Sprintf("%s/%s", ns, f)] = body
+		}
+	}
+	return files
+}
+
+// handMaintained checks if experiment file has the hand-maintained marker in one of its leading comment lines
+func handMaintained(body string) bool {
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "#") {
+			return false
+		}
+		if strings.TrimSpace(strings.TrimPrefix(line, "#")) == HandMaintainedKey {
+			return true
+		}
+	}
+	return false
+}
+
+// readSpecFiles reads experiment files by relative path, only experiment type dirs are read the same way monkey does,
+// other files in dir are ignored, dir may not exist
+func readSpecFiles(dir string) (map[string]string, error) {
+	files := make(map[string]string)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return files, nil
+	}
+	dirs, err := experimentDirs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range dirs {
+		for _, expType := range KnownExperimentTypes {
+			paths, err := filepath.Glob(filepath.Join(d, expType, "*.yaml"))
+			if err != nil {
+				return nil, err
+			}
+			for _, path := range paths {
+				if filepath.Base(path) == KustomizationFileName {
+					continue
+				}
+				rel, err := filepath.Rel(dir, path)
+				if err != nil {
+					return nil, err
+				}
+				data, err := os.ReadFile(path)
+				if err != nil {
+					return nil, err
+				}
+				files[filepath.ToSlash(rel)] = string(data)
+			}
+		}
+	}
+	return files, nil
+}
+
+// DiffFiles compares generated experiment files with experiments dir
+func DiffFiles(dir string, files map[string]string) (*SpecsDiff, error) {
+	existing, err := readSpecFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	d := &SpecsDiff{
+		Added:   make([]string, 0),
+		Removed: make([]string, 0),
+		Changed: make([]string, 0),
+		Kept:    make([]string, 0),
+		Unified: make(map[string]string),
+	}
+	for f, body := range files {
+		old, ok := existing[f]
+		switch {
+		case !ok:
+			d.Added = append(d.Added, f)
+		case old == body:
+		case handMaintained(old):
+			d.Kept = append(d.Kept, f)
+		default:
+			d.Changed = append(d.Changed, f)
+			d.Unified[f], err = difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+				A:        difflib.SplitLines(old),
+				B:        difflib.SplitLines(body),
+				FromFile: "a/" + f,
+				ToFile:   "b/" + f,
+				Context:  3,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	for f, old := range existing {
+		if _, ok := files[f]; ok {
+			continue
+		}
+		if handMaintained(old) {
+			d.Kept = append(d.Kept, f)
+			continue
+		}
+		d.Removed = append(d.Removed, f)
+	}
+	for _, s := range [][]string{d.Added, d.Removed, d.Changed, d.Kept} {
+		sort.Strings(s)
+	}
+	return d, nil
+}
+
+// MergeFiles writes generated experiment files to experiments dir without wiping it,
+// stale files are removed, hand-maintained files are never overwritten or removed
+func MergeFiles(dir string, files map[string]string) (*SpecsDiff, error) {
+	d, err := DiffFiles(dir, files)
+	if err != nil {
+		return nil, err
+	}
+	L.Info().
+		Str("Dir", dir).
+		Int("Added", len(d.Added)).
+		Int("Removed", len(d.Removed)).
+		Int("Changed", len(d.Changed)).
+		Strs("Kept", d.Kept).
+		Msg("Merging experiments into a dir")
+	for _, f := range d.Removed {
+		path := filepath.Join(dir, filepath.FromSlash(f))
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+		// remove experiment type dir if it's empty now
+		_ = os.Remove(filepath.Dir(path))
+	}
+	for _, f := range lo.Union(d.Added, d.Changed) {
+		path := filepath.Join(dir, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, []byte(files[f]), os.ModePerm); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// specFiles generates experiments for namespaces without writing them, layout is the same as in GenerateSpecsForNamespaces
+func (m *Controller) specFiles(namespaces []string) (map[string]string, error) {
+	if len(namespaces) == 1 {
+		podsInfo, err := m.GetPodsInfo(namespaces[0])
+		if err != nil {
+			return nil, err
+		}
+		csp, _, err := m.buildSpecs(namespaces[0], podsInfo)
+		if err != nil {
+			return nil, err
+		}
+		return csp.Files(), nil
+	}
+	podsInfo, err := m.getPodsInfoForNamespaces(namespaces)
+	if err != nil {
+		return nil, err
+	}
+	allSpecs, err := m.buildSpecsForNamespaces(podsInfo)
+	if err != nil {
+		return nil, err
+	}
+	return namespaceFiles(allSpecs), nil
+}
+
+// DiffSpecsForNamespaces generates experiments and compares them with experiments dir without writing anything
+func (m *Controller) DiffSpecsForNamespaces(namespaces []string) (*SpecsDiff, error) {
+	files, err := m.specFiles(namespaces)
+	if err != nil {
+		return nil, err
+	}
+	return DiffFiles(m.cfg.Havoc.Dir, files)
+}
+
+// MergeSpecsForNamespaces generates experiments and merges them into experiments dir, preserving hand-maintained files
+func (m *Controller) MergeSpecsForNamespaces(namespaces []string) (*SpecsDiff, error) {
+	files, err := m.specFiles(namespaces)
+	if err != nil {
+		return nil, err
+	}
+	return MergeFiles(m.cfg.Havoc.Dir, files)
+}
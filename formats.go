@@ -0,0 +1,341 @@
+package havoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ErrUnknownOutputFormat    = "unknown output format, only dir, yaml, kustomize, helm and json are supported"
+	ErrUnreadableOutputFormat = "experiments dir is a Helm chart or a YAML stream, run and apply only read dir, kustomize and json output formats"
+)
+
+// Output formats of generated experiments
+const (
+	// OutputFormatDir is one YAML file per experiment in a dir per experiment type
+	OutputFormatDir = "dir"
+	// OutputFormatYAML is a single multi-document YAML stream
+	OutputFormatYAML = "yaml"
+	// OutputFormatKustomize is a Kustomize base with a kustomization.yaml in every dir
+	OutputFormatKustomize = "kustomize"
+	// OutputFormatHelm is a Helm chart with experiment durations and modes in values
+	OutputFormatHelm = "helm"
+	// OutputFormatJSON is the dir layout with a JSON index of all manifests
+	OutputFormatJSON = "json"
+)
+
+const (
+	YAMLStreamFileName    = "experiments.yaml"
+	KustomizationFileName = "kustomization.yaml"
+	JSONIndexFileName     = "index.json"
+	HelmChartFileName     = "Chart.yaml"
+	HelmChartName         = "havoc-experiments"
+)
+
+var (
+	OutputFormats = []string{
+		OutputFormatDir,
+		OutputFormatYAML,
+		OutputFormatKustomize,
+		OutputFormatHelm,
+		OutputFormatJSON,
+	}
+)
+
+// ManifestIndexEntry is a JSON index entry of one generated manifest
+type ManifestIndexEntry struct {
+	Path      string `json:"path"`
+	Type      string `json:"type"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+type helmExperimentValues struct {
+	Enabled  bool   `yaml:"enabled"`
+	Duration string `yaml:"duration,omitempty"`
+	Mode     string `yaml:"mode,omitempty"`
+	Value    string `yaml:"value,omitempty"`
+}
+
+// ValidateOutputFormat checks output format is supported
+func ValidateOutputFormat(format string) error {
+	if !sliceContains(format, OutputFormats) {
+		return errors.Wrap(errors.New(ErrUnknownOutputFormat), format)
+	}
+	return nil
+}
+
+// readableExperimentsDir checks experiments dir wasn't written in an output format that can't be read by experiment type dirs
+func readableExperimentsDir(dir string) error {
+	for _, f := range []string{HelmChartFileName, YAMLStreamFileName} {
+		if _, err := os.Stat(filepath.Join(dir, f)); err == nil {
+			return errors.Wrap(errors.New(ErrUnreadableOutputFormat), dir)
+		}
+	}
+	return nil
+}
+
+// sortedPaths returns experiment file paths in lexicographical order
+func sortedPaths(files map[string]string) []string {
+	paths := make([]string, 0)
+	for f := range files {
+		paths = append(paths, f)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// chaosMeshFiles filters out experiments of custom kinds, they are not Kubernetes resources and can't be applied by GitOps tools
+func chaosMeshFiles(files map[string]string) (map[string]string, error) {
+	filtered := make(map[string]string)
+	for f, body := range files {
+		var crd CRD
+		if err := yaml.Unmarshal([]byte(body), &crd); err != nil {
+			return nil, errors.Wrap(err, f)
+		}
+		if _, ok := ExperimentTypesToCRDNames[crd.Kind]; ok {
+			filtered[f] = body
+		}
+	}
+	return filtered, nil
+}
+
+// formatFiles transforms experiment files to output format files, paths are relative to experiments dir
+func formatFiles(format string, files map[string]string) (map[string]string, error) {
+	if err := ValidateOutputFormat(format); err != nil {
+		return nil, err
+	}
+	switch format {
+	case OutputFormatYAML:
+		return yamlStreamFiles(files)
+	case OutputFormatKustomize:
+		return kustomizeFiles(files)
+	case OutputFormatHelm:
+		return helmFiles(files)
+	case OutputFormatJSON:
+		return jsonIndexFiles(files)
+	}
+	return files, nil
+}
+
+func yamlStreamFiles(files map[string]string) (map[string]string, error) {
+	files, err := chaosMeshFiles(files)
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]string, 0)
+	for _, f := range sortedPaths(files) {
+		docs = append(docs, fmt.Sprintf("# Source: %s\n%s\n", f, strings.TrimSpace(files[f])))
+	}
+	return map[string]string{YAMLStreamFileName: strings.Join(docs, "---\n")}, nil
+}
+
+// kustomizeFiles adds a kustomization.yaml to every dir, listing its manifests and subdirectories as resources
+func kustomizeFiles(files map[string]string) (map[string]string, error) {
+	files, err := chaosMeshFiles(files)
+	if err != nil {
+		return nil, err
+	}
+	resources := make(map[string][]string)
+	for _, f := range sortedPaths(files) {
+		dir, name := path.Split(f)
+		dir = strings.TrimSuffix(dir, "/")
+		resources[dir] = append(resources[dir], name)
+		for dir != "" {
+			parent, child := path.Split(dir)
+			parent = strings.TrimSuffix(parent, "/")
+			if !sliceContains(child, resources[parent]) {
+				resources[parent] = append(resources[parent], child)
+			}
+			dir = parent
+		}
+	}
+	out := make(map[string]string)
+	for f, body := range files {
+		out[f] = body
+	}
+	for dir, res := range resources {
+		sort.Strings(res)
+		var sb strings.Builder
+		sb.WriteString("apiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\nresources:\n")
+		for _, r := range res {
+			sb.WriteString(fmt.Sprintf("  - %s\n", r))
+		}
+		out[path.Join(dir, KustomizationFileName)] = sb.String()
+	}
+	return out, nil
+}
+
+// helmValuesKey returns experiment key in chart values, namespace is prepended if experiments were generated for multiple namespaces
+func helmValuesKey(f string) string {
+	key := strings.TrimSuffix(path.Base(f), ".yaml")
+	if parts := strings.Split(f, "/"); len(parts) > 2 {
+		key = fmt.Sprintf("%s-%s", parts[0], key)
+	}
+	return key
+}
+
+// helmTemplate replaces experiment spec duration, mode and value with references to Helm values and returns their values
+func helmTemplate(body string) (string, *helmExperimentValues, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(body), &doc); err != nil {
+		return "", nil, err
+	}
+	v := &helmExperimentValues{Enabled: true}
+	if len(doc.Content) == 0 {
+		return body, v, nil
+	}
+	if spec := mappingValue(doc.Content[0], "spec"); spec != nil && spec.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(spec.Content); i += 2 {
+			key, val := spec.Content[i], spec.Content[i+1]
+			if val.Kind != yaml.ScalarNode {
+				continue
+			}
+			switch key.Value {
+			case "duration":
+				v.Duration = val.Value
+			case "mode":
+				v.Mode = val.Value
+			case "value":
+				v.Value = val.Value
+			default:
+				continue
+			}
+			val.SetString(fmt.Sprintf("{{ $e.%s }}", key.Value))
+			val.Style = yaml.SingleQuotedStyle
+		}
+	}
+	var sb strings.Builder
+	enc := yaml.NewEncoder(&sb)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return "", nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return "", nil, err
+	}
+	return sb.String(), v, nil
+}
+
+// mappingValue returns value node of a mapping key, nil if node is not a mapping or has no such key
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// helmFiles makes a chart with every experiment as a template, durations and modes are moved to values
+// and every experiment can be disabled with "enabled: false"
+func helmFiles(files map[string]string) (map[string]string, error) {
+	files, err := chaosMeshFiles(files)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]*helmExperimentValues)
+	out := make(map[string]string)
+	for _, f := range sortedPaths(files) {
+		key := helmValuesKey(f)
+		tpl, v, err := helmTemplate(files[f])
+		if err != nil {
+			return nil, errors.Wrap(err, f)
+		}
+		values[key] = v
+		out[path.Join("templates", f)] = fmt.Sprintf(
+			"{{- $e := index .Values.experiments %q }}\n{{- if $e.enabled }}\n%s\n{{- end }}\n",
+			key,
+			strings.TrimSpace(tpl),
+		)
+	}
+	d, err := yaml.Marshal(map[string]interface{}{"experiments": values})
+	if err != nil {
+		return nil, err
+	}
+	out["values.yaml"] = string(d)
+	out[HelmChartFileName] = fmt.Sprintf(`apiVersion: v2
+name: %s
+description: Chaos Mesh experiments generated by havoc
+type: application
+version: 0.1.0
+`, HelmChartName)
+	return out, nil
+}
+
+// jsonIndexFiles adds a JSON index of all manifests to the dir layout
+func jsonIndexFiles(files map[string]string) (map[string]string, error) {
+	index := make([]*ManifestIndexEntry, 0)
+	for _, f := range sortedPaths(files) {
+		var crd CRD
+		if err := yaml.Unmarshal([]byte(files[f]), &crd); err != nil {
+			return nil, errors.Wrap(err, f)
+		}
+		index = append(index, &ManifestIndexEntry{
+			Path:      f,
+			Type:      path.Base(path.Dir(f)),
+			Kind:      crd.Kind,
+			Name:      crd.Metadata.Name,
+			Namespace: crd.Metadata.Namespace,
+		})
+	}
+	d, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]string)
+	for f, body := range files {
+		out[f] = body
+	}
+	out[JSONIndexFileName] = string(d) + "\n"
+	return out, nil
+}
+
+// writeFiles rewrites dir with files by relative path
+func writeFiles(dir string, files map[string]string) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	for f, body := range files {
+		p := filepath.Join(dir, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+			return err
+		}
+		if err := os.WriteFile(p, []byte(body), os.ModePerm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportSpecsForNamespaces generates experiments for namespaces and writes them to experiments dir in output format
+func (m *Controller) ExportSpecsForNamespaces(namespaces []string, format string) error {
+	if err := ValidateOutputFormat(format); err != nil {
+		return err
+	}
+	files, err := m.specFiles(namespaces)
+	if err != nil {
+		return err
+	}
+	out, err := formatFiles(format, files)
+	if err != nil {
+		return err
+	}
+	L.Info().Str("Dir", m.cfg.Havoc.Dir).Str("Format", format).Int("Files", len(out)).Msg("Writing experiments to a dir")
+	return writeFiles(m.cfg.Havoc.Dir, out)
+}
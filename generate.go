@@ -10,7 +10,6 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
-	"sort"
 	"strconv"
 	"strings"
 	"text/template"
@@ -27,6 +26,8 @@ const (
 	ErrExperimentTimeout = "waiting for experiment to finish timed out"
 	ErrExperimentApply   = "error applying experiment manifest"
 	ErrInvalidCustomKind = "invalid custom Kind of experiment"
+
+	ErrNotSinglePodExperiment = "experiment type is not a single pod experiment"
 )
 
 const (
@@ -71,57 +72,29 @@ type CommonExperimentMeta struct {
 	} `yaml:"metadata"`
 }
 
+// HTTPExperiment is template data of http experiments, one per OpenAPI path and method
 type HTTPExperiment struct {
-	ExperimentName string
-	Metadata       *Metadata
-	Namespace      string
-	Mode           string
-	ModeValue      string
-	Selector       string
-	PodName        string
-	Port           int64
-	Target         string
-	Path           string
-	Method         string
-	Abort          bool
-	Duration       string
+	ExperimentName      string
+	Metadata            *Metadata
+	Namespace           string
+	Mode                string
+	ModeValue           string
+	Selector            string
+	ExpressionSelectors string
+	PodName             string
+	Port                int64
+	Target              string
+	Path                string
+	Method              string
+	Abort               bool
+	Duration            string
 }
 
 func (m HTTPExperiment) String() (string, error) {
-	tpl := `
-kind: HTTPChaos
-apiVersion: chaos-mesh.org/v1alpha1
-metadata:
-  name: {{ .ExperimentName }}
-spec:
-  mode: {{ .Mode }}
-  {{- if .ModeValue }}
-  value: '{{ .ModeValue }}'
-  {{- end }}
-  selector:
-    namespaces:
-      - {{ .Namespace }}
-    {{- if .Selector}}
-    labelSelectors:
-      {{ .Selector }}
-	{{- else}}
-    fieldSelectors:
-      metadata.name: {{ .PodName }}	
-	{{- end}}
-  target: Request
-  port: {{ .Port }}
-  method: {{ .Method }}
-  path: {{ .Path }}
-  abort: {{ .Abort }}
-  duration: {{ .Duration }}
-`
-	return MarshalTemplate(
-		m,
-		uuid.NewString(),
-		tpl,
-	)
+	return objectString(m)
 }
 
+// BlockchainRewindHeadExperiment is template data of blockchain_rewind_head experiments, rendered as a custom kind, not a Chaos Mesh object
 type BlockchainRewindHeadExperiment struct {
 	ExperimentName        string    `yaml:"experimentName"`
 	Metadata              *Metadata `yaml:"metadata"`
@@ -157,284 +130,110 @@ blocks: {{ .Blocks }}
 	)
 }
 
+// NetworkChaosExperiment is template data of latency and group-latency experiments
 type NetworkChaosExperiment struct {
-	ExperimentName string
-	Mode           string
-	ModeValue      string
-	Namespace      string
-	Duration       string
-	Latency        string
-	PodName        string
-	Selector       string
+	ExperimentName      string
+	Mode                string
+	ModeValue           string
+	Namespace           string
+	Duration            string
+	Latency             string
+	PodName             string
+	Selector            string
+	ExpressionSelectors string
 }
 
 func (m NetworkChaosExperiment) String() (string, error) {
-	tpl := `
-kind: NetworkChaos
-apiVersion: chaos-mesh.org/v1alpha1
-metadata:
-  name: {{ .ExperimentName }}
-  namespace: {{ .Namespace }}
-spec:
-  selector:
-    namespaces:
-      - {{ .Namespace }}
-    {{- if .Selector}}
-    labelSelectors:
-      {{ .Selector }}
-	{{- else}}
-    fieldSelectors:
-      metadata.name: {{ .PodName }}	
-	{{- end}}
-  mode: {{ .Mode }}
-  {{- if .ModeValue }}
-  value: '{{ .ModeValue }}'
-  {{- end }}
-  action: delay
-  duration: {{ .Duration }}
-  delay:
-    latency: {{ .Latency }}
-  direction: from
-  target:
-    selector:
-      namespaces:
-        - {{ .Namespace }}
-      {{- if .Selector}}
-      labelSelectors:
-        {{ .Selector }}
-	  {{- else}}
-      fieldSelectors:
-        metadata.name: {{ .PodName }}	
-	  {{- end}}
-    mode: {{ .Mode }}
-    {{- if .ModeValue }}
-    value: '{{ .ModeValue }}'
-    {{- end }}
-`
-	return MarshalTemplate(
-		m,
-		uuid.NewString(),
-		tpl,
-	)
+	return objectString(m)
 }
 
+// NetworkChaosGroupPartitionExperiment is template data of group-partition experiments between two label selectors
 type NetworkChaosGroupPartitionExperiment struct {
-	ExperimentName string
-	ModeTo         string
-	ModeToValue    string
-	ModeFrom       string
-	ModeFromValue  string
-	Direction      string
-	Namespace      string
-	Duration       string
-	SelectorFrom   string
-	SelectorTo     string
+	ExperimentName  string
+	ModeTo          string
+	ModeToValue     string
+	ModeFrom        string
+	ModeFromValue   string
+	Direction       string
+	Namespace       string
+	TargetNamespace string
+	Duration        string
+	SelectorFrom    string
+	SelectorTo      string
+	// TargetExpressionSelectors is a YAML flow sequence of ChaosMesh expressionSelectors
+	TargetExpressionSelectors string
 }
 
 func (m NetworkChaosGroupPartitionExperiment) String() (string, error) {
-	tpl := `
-kind: NetworkChaos
-apiVersion: chaos-mesh.org/v1alpha1
-metadata:
-  name: {{ .ExperimentName }}
-  namespace: {{ .Namespace }}
-spec:
-  selector:
-    namespaces:
-      - {{ .Namespace }}
-    labelSelectors:
-      {{ .SelectorFrom }}
-  action: partition
-  mode: {{ .ModeFrom }}
-  {{- if .ModeFromValue }}
-  value: '{{ .ModeFromValue }}'
-  {{- end }}
-  duration: {{ .Duration }}
-  direction: {{ .Direction }}
-  target:
-    mode: {{ .ModeTo }}
-    {{- if .ModeToValue }}
-    value: '{{ .ModeToValue }}'
-    {{- end }}
-    selector:
-      namespaces:
-        - {{ .Namespace }}
-      labelSelectors:
-        {{ .SelectorTo }}
-`
-	return MarshalTemplate(
-		m,
-		uuid.NewString(),
-		tpl,
-	)
+	return objectString(m)
 }
 
+// NetworkChaosExternalPartitionExperiment is template data of external experiments, partitioning a pod from an external URL
 type NetworkChaosExternalPartitionExperiment struct {
 	ExperimentName string
 	Namespace      string
 	Duration       string
 	PodName        string
-	ExternalURL    string
+	// ExternalURL is an unquoted external target, user templates should quote it
+	ExternalURL string
 }
 
 func (m NetworkChaosExternalPartitionExperiment) String() (string, error) {
-	tpl := `
-kind: NetworkChaos
-apiVersion: chaos-mesh.org/v1alpha1
-metadata:
-  name: {{ .ExperimentName }}
-  namespace: {{ .Namespace }}
-spec:
-  selector:
-    namespaces:
-      - {{ .Namespace }}
-  mode: all
-  action: partition
-  duration: {{ .Duration }}
-  direction: to
-  target:
-    selector:
-      namespaces:
-        - {{ .Namespace }}
-    mode: all
-  externalTargets:
-    - {{ .ExternalURL }}
-`
-	return MarshalTemplate(
-		m,
-		uuid.NewString(),
-		tpl,
-	)
+	return objectString(m)
 }
 
+// PodFailureExperiment is template data of failure and group-failure experiments
 type PodFailureExperiment struct {
-	ExperimentName string
-	Mode           string
-	ModeValue      string
-	Namespace      string
-	Duration       string
-	PodName        string
-	Selector       string
+	ExperimentName      string
+	Mode                string
+	ModeValue           string
+	Namespace           string
+	Duration            string
+	PodName             string
+	Selector            string
+	ExpressionSelectors string
 }
 
 func (m PodFailureExperiment) String() (string, error) {
-	tpl := `
-apiVersion: chaos-mesh.org/v1alpha1
-kind: PodChaos
-metadata:
-  name: {{ .ExperimentName }}
-  namespace: {{ .Namespace }}
-spec:
-  action: pod-failure
-  mode: {{ .Mode }}
-  {{- if .ModeValue }}
-  value: '{{ .ModeValue }}'
-  {{- end }}
-  duration: {{ .Duration }}
-  selector:
-    {{- if .Selector}}
-    labelSelectors:
-      {{ .Selector }}
-	{{- else}}
-    fieldSelectors:
-      metadata.name: {{ .PodName }}	
-	{{- end}}
-`
-	return MarshalTemplate(
-		m,
-		uuid.NewString(),
-		tpl,
-	)
+	return objectString(m)
 }
 
+// PodStressCPUExperiment is template data of cpu and group-cpu experiments
 type PodStressCPUExperiment struct {
-	ExperimentName string
-	Mode           string
-	ModeValue      string
-	Namespace      string
-	Workers        int
-	Load           int
-	Duration       string
-	PodName        string
-	Selector       string
+	ExperimentName      string
+	Mode                string
+	ModeValue           string
+	Namespace           string
+	Workers             int
+	Load                int
+	Duration            string
+	PodName             string
+	Selector            string
+	ExpressionSelectors string
+	ContainerNames      []string
 }
 
 func (m PodStressCPUExperiment) String() (string, error) {
-	tpl := `
-apiVersion: chaos-mesh.org/v1alpha1
-kind: StressChaos
-metadata:
-  name: {{ .ExperimentName }}
-  namespace: {{ .Namespace }}
-spec:
-  mode: {{ .Mode }}
-  {{- if .ModeValue }}
-  value: '{{ .ModeValue }}'
-  {{- end }}
-  duration: {{ .Duration }}
-  selector:
-    {{- if .Selector}}
-    labelSelectors:
-      {{ .Selector }}
-	{{- else}}
-    fieldSelectors:
-      metadata.name: {{ .PodName }}	
-	{{- end}}
-  stressors:
-    cpu:
-      workers: {{ .Workers }}
-      load: {{ .Load }}
-`
-	return MarshalTemplate(
-		m,
-		uuid.NewString(),
-		tpl,
-	)
+	return objectString(m)
 }
 
+// PodStressMemoryExperiment is template data of memory and group-memory experiments
 type PodStressMemoryExperiment struct {
-	ExperimentName string
-	Mode           string
-	ModeValue      string
-	Namespace      string
-	Workers        int
-	Memory         string
-	Duration       string
-	PodName        string
-	Selector       string
+	ExperimentName      string
+	Mode                string
+	ModeValue           string
+	Namespace           string
+	Workers             int
+	Memory              string
+	Duration            string
+	PodName             string
+	Selector            string
+	ExpressionSelectors string
+	ContainerNames      []string
 }
 
 func (m PodStressMemoryExperiment) String() (string, error) {
-	tpl := `
-apiVersion: chaos-mesh.org/v1alpha1
-kind: StressChaos
-metadata:
-  name: {{ .ExperimentName }}
-  namespace: {{ .Namespace }}
-spec:
-  mode: {{ .Mode }}
-  {{- if .ModeValue }}
-  value: '{{ .ModeValue }}'
-  {{- end }}
-  duration: {{ .Duration }}
-  selector:
-    {{- if .Selector}}
-    labelSelectors:
-      {{ .Selector }}
-	{{- else}}
-    fieldSelectors:
-      metadata.name: {{ .PodName }}	
-	{{- end}}
-  stressors:
-    memory:
-      workers: {{ .Workers }}
-      size: {{ .Memory }}
-`
-	return MarshalTemplate(
-		m,
-		uuid.NewString(),
-		tpl,
-	)
+	return objectString(m)
 }
 
 type CRD struct {
@@ -459,9 +258,13 @@ func NewNamedExperiment(expPath string) (*NamedExperiment, error) {
 	if err != nil {
 		return nil, err
 	}
+	return newNamedExperiment(expPath, data)
+}
 
+// newNamedExperiment parses experiment manifest, path is where experiment is or would be written
+func newNamedExperiment(expPath string, data []byte) (*NamedExperiment, error) {
 	var exp CRD
-	err = yaml.Unmarshal(data, &exp)
+	err := yaml.Unmarshal(data, &exp)
 	if err != nil {
 		return nil, err
 	}
@@ -478,30 +281,6 @@ func NewNamedExperiment(expPath string) (*NamedExperiment, error) {
 	}, nil
 }
 
-func (m *Controller) readExistingExperimentTypes(dir string) ([]string, error) {
-	expTypes := make([]string, 0)
-	err := filepath.Walk(
-		dir,
-		func(path string, info fs.FileInfo, err error) error {
-			if err != nil {
-				return err
-			}
-			if info.IsDir() && info.Name() != dir {
-				expTypes = append(expTypes, info.Name())
-				return nil
-			}
-			return err
-		})
-	if err != nil {
-		return nil, err
-	}
-	sort.Slice(expTypes, func(i, j int) bool {
-		return expTypes[i] < expTypes[j]
-	})
-	L.Info().Strs("Order", expTypes).Msg("Order of experiment dirs execution")
-	return expTypes, nil
-}
-
 func (m *Controller) ReadExperimentsFromDir(expTypes []string, dir string) ([]*NamedExperiment, error) {
 	expData := make([]*NamedExperiment, 0)
 	for _, expType := range expTypes {
@@ -516,7 +295,7 @@ func (m *Controller) ReadExperimentsFromDir(expTypes []string, dir string) ([]*N
 				if err != nil {
 					return err
 				}
-				if info.IsDir() {
+				if info.IsDir() || info.Name() == KustomizationFileName {
 					return nil
 				}
 				exp, err := NewNamedExperiment(path)
@@ -542,7 +321,70 @@ func maybeFailAll(e lo.Entry[string, int], origValue string) string {
 	return origValue
 }
 
+// singleExperiment generates an experiment of a single pod type for one pod, selected by pod name or by a stable label selector,
+// containers are set only for experiment types that support them
+func (m *Controller) singleExperiment(expType string, namespace string, name string, podName string, selector string, containers []string) (experimentObject, error) {
+	experimentName := fmt.Sprintf("%s-%s", expType, name)
+	switch expType {
+	case ChaosTypeFailure:
+		return PodFailureExperiment{
+			Namespace:      namespace,
+			ExperimentName: experimentName,
+			Mode:           "one",
+			Duration:       m.cfg.Havoc.Failure.Duration,
+			PodName:        podName,
+			Selector:       selector,
+		}, nil
+	case ChaosTypeLatency:
+		return NetworkChaosExperiment{
+			Namespace:      namespace,
+			ExperimentName: experimentName,
+			Mode:           "one",
+			Duration:       m.cfg.Havoc.Latency.Duration,
+			Latency:        m.cfg.Havoc.Latency.Latency,
+			PodName:        podName,
+			Selector:       selector,
+		}, nil
+	case ChaosTypeStressCPU:
+		return PodStressCPUExperiment{
+			Namespace:      namespace,
+			ExperimentName: experimentName,
+			Duration:       m.cfg.Havoc.StressCPU.Duration,
+			Workers:        m.cfg.Havoc.StressCPU.Workers,
+			Load:           m.cfg.Havoc.StressCPU.Load,
+			Mode:           "one",
+			PodName:        podName,
+			Selector:       selector,
+			ContainerNames: containers,
+		}, nil
+	case ChaosTypeStressMemory:
+		return PodStressMemoryExperiment{
+			Namespace:      namespace,
+			ExperimentName: experimentName,
+			Duration:       m.cfg.Havoc.StressMemory.Duration,
+			Workers:        m.cfg.Havoc.StressMemory.Workers,
+			Memory:         m.cfg.Havoc.StressMemory.Memory,
+			Mode:           "one",
+			PodName:        podName,
+			Selector:       selector,
+			ContainerNames: containers,
+		}, nil
+	case ChaosTypeContainerKill:
+		return ContainerKillExperiment{
+			Namespace:      namespace,
+			ExperimentName: experimentName,
+			Mode:           "one",
+			PodName:        podName,
+			Selector:       selector,
+			ContainerNames: containers,
+		}, nil
+	default:
+		return nil, errors.Wrap(errors.New(ErrNotSinglePodExperiment), expType)
+	}
+}
+
 func (m *Controller) generate(
+	expTypes []string,
 	namespace string,
 	oapiSpecs []*OAPISpecData,
 	allPodsInfo map[string][]*PodResponse,
@@ -550,26 +392,30 @@ func (m *Controller) generate(
 	groupLabels []lo.Entry[string, int],
 	netLabels [][]string,
 ) (*ChaosSpecs, error) {
-	allExperimentsByType := make(map[string]map[string]string)
-	for _, expType := range m.cfg.Havoc.ExperimentTypes {
-		experiments := make(map[string]string)
+	specs := &ChaosSpecs{}
+	for _, expType := range expTypes {
+		experiments := specs.addType(expType)
 		switch expType {
 		case ChaosTypeHTTP:
 			for _, entry := range groupLabels {
-				if _, ok := m.cfg.Havoc.OpenAPI.Mapping[m.groupValueFromLabelSelector(entry.Key)]; ok {
-					if err := m.generateOAPIExperiments(experiments, namespace, entry, oapiSpecs); err != nil {
-						return nil, err
-					}
+				if podsOptedOut(expType, allPodsInfo[entry.Key]...) {
+					continue
+				}
+				if err := m.generateOAPIExperiments(experiments, namespace, entry, oapiSpecs); err != nil {
+					return nil, err
 				}
 			}
 		case ChaosTypeBlockchainSetHead:
 			for _, p := range allPodsInfo {
 				for _, pi := range p {
+					if podsOptedOut(expType, pi) {
+						continue
+					}
 					for _, nodeCfg := range m.cfg.Havoc.BlockchainRewindHead.NodesConfig {
 						if strings.Contains(pi.Metadata.Name, nodeCfg.ExecutorPodPrefix) {
 							for _, b := range nodeCfg.Blocks {
 								name := fmt.Sprintf("%s-%s-%d", ChaosTypeBlockchainSetHead, pi.Metadata.Name, b)
-								experiment, err := BlockchainRewindHeadExperiment{
+								experiment, err := m.renderExperiment(BlockchainRewindHeadExperiment{
 									ExperimentName:        name,
 									Metadata:              &Metadata{Name: name},
 									Namespace:             namespace,
@@ -577,302 +423,291 @@ func (m *Controller) generate(
 									PodName:               pi.Metadata.Name,
 									ExecutorContainerName: nodeCfg.ExecutorContainerName,
 									Blocks:                b,
-								}.String()
+								})
 								if err != nil {
 									return nil, err
 								}
 								shortName := fmt.Sprintf("%s-%d", pi.Metadata.Name, b)
-								experiments[shortName] = experiment
+								experiments.manifests[shortName] = experiment
 							}
 						}
 					}
 				}
 			}
 		case ChaosTypePartitionExternal:
-			if m.cfg.Havoc.ExternalTargets == nil {
+			if m.cfg.Havoc.ExternalTargets == nil || podsOptedOut(expType, lo.Flatten(lo.Values(allPodsInfo))...) {
 				continue
 			}
 			for _, u := range m.cfg.Havoc.ExternalTargets.URLs {
 				nsAndURLHash := fmt.Sprintf("%s-%s", namespace, urlHash(u))
-				experiment, err := NetworkChaosExternalPartitionExperiment{
+				err := m.addExperiment(experiments, nsAndURLHash, NetworkChaosExternalPartitionExperiment{
 					Namespace:      namespace,
 					ExperimentName: fmt.Sprintf("%s-%s", ChaosTypePartitionExternal, nsAndURLHash),
 					Duration:       m.cfg.Havoc.ExternalTargets.Duration,
-					ExternalURL:    fmt.Sprintf("'%s'", u),
-				}.String()
+					ExternalURL:    u,
+				})
 				if err != nil {
 					return nil, err
 				}
-				experiments[nsAndURLHash] = experiment
 			}
 		case ChaosTypePartitionGroup:
+			optedOutGroups := m.optedOutNetworkGroups(lo.Flatten(lo.Values(allPodsInfo)))
 			for _, pair := range netLabels {
-				for _, groupModeValue := range m.cfg.Havoc.NetworkPartition.GroupPercentage {
-					sanitizedLabel := sanitizeLabel(fmt.Sprintf("%s-to-%s", pair[0], pair[1]))
-					sanitizedLabel = fmt.Sprintf("%s-%s-perc", sanitizedLabel, groupModeValue)
-					experiment, err := NetworkChaosGroupPartitionExperiment{
-						Namespace:      namespace,
-						ExperimentName: fmt.Sprintf("%s-%s", ChaosTypePartitionGroup, sanitizedLabel),
-						Duration:       m.cfg.Havoc.NetworkPartition.Duration,
-						ModeFrom:       "fixed-percent",
-						ModeFromValue:  groupModeValue,
-						ModeTo:         "fixed-percent",
-						ModeToValue:    groupModeValue,
-						Direction:      "from",
-						SelectorFrom:   pair[0],
-						SelectorTo:     pair[1],
-					}.String()
-					if err != nil {
-						return nil, err
-					}
-					experiments[sanitizedLabel] = experiment
-				}
-				for _, groupModeValue := range m.cfg.Havoc.NetworkPartition.GroupFixed {
-					sanitizedLabel := sanitizeLabel(fmt.Sprintf("%s-to-%s", pair[0], pair[1]))
-					sanitizedLabel = fmt.Sprintf("%s-%s-fixed", sanitizedLabel, groupModeValue)
-					experiment, err := NetworkChaosGroupPartitionExperiment{
-						Namespace:      namespace,
-						ExperimentName: fmt.Sprintf("%s-%s", ChaosTypePartitionGroup, sanitizedLabel),
-						Duration:       m.cfg.Havoc.NetworkPartition.Duration,
-						ModeFrom:       "fixed-percent",
-						ModeFromValue:  groupModeValue,
-						ModeTo:         "fixed-percent",
-						ModeToValue:    groupModeValue,
-						Direction:      "from",
-						SelectorFrom:   pair[0],
-						SelectorTo:     pair[1],
-					}.String()
-					if err != nil {
-						return nil, err
-					}
-					experiments[sanitizedLabel] = experiment
+				if sliceContains(pair[0], optedOutGroups) || sliceContains(pair[1], optedOutGroups) {
+					continue
 				}
-			}
-		case ChaosTypeFailure:
-			for _, pi := range podsInfo {
-				experiment, err := PodFailureExperiment{
-					Namespace:      namespace,
-					ExperimentName: fmt.Sprintf("%s-%s", ChaosTypeFailure, pi.Metadata.Name),
-					Mode:           "one",
-					Duration:       m.cfg.Havoc.Failure.Duration,
-					PodName:        pi.Metadata.Name,
-				}.String()
-				if err != nil {
+				from := partitionSide{Namespace: namespace, Name: pair[0], Selector: pair[0]}
+				to := partitionSide{Name: pair[1], Selector: pair[1]}
+				if err := m.generatePartition(experiments, from, to, m.partitionModes()); err != nil {
 					return nil, err
 				}
-				experiments[pi.Metadata.Name] = experiment
 			}
-		case ChaosTypeLatency:
+		case ChaosTypeFailure, ChaosTypeLatency, ChaosTypeStressCPU, ChaosTypeStressMemory, ChaosTypeContainerKill:
 			for _, pi := range podsInfo {
-				experiment, err := NetworkChaosExperiment{
-					Namespace:      namespace,
-					ExperimentName: fmt.Sprintf("%s-%s", ChaosTypeLatency, pi.Metadata.Name),
-					Mode:           "one",
-					Duration:       m.cfg.Havoc.Latency.Duration,
-					Latency:        m.cfg.Havoc.Latency.Latency,
-					PodName:        pi.Metadata.Name,
-				}.String()
-				if err != nil {
-					return nil, err
+				if podsOptedOut(expType, pi) {
+					continue
 				}
-				experiments[pi.Metadata.Name] = experiment
-			}
-		case ChaosTypeStressCPU:
-			for _, pi := range podsInfo {
-				experiment, err := PodStressCPUExperiment{
-					Namespace:      namespace,
-					ExperimentName: fmt.Sprintf("%s-%s", ChaosTypeStressCPU, pi.Metadata.Name),
-					Duration:       m.cfg.Havoc.StressCPU.Duration,
-					Workers:        m.cfg.Havoc.StressCPU.Workers,
-					Load:           m.cfg.Havoc.StressCPU.Load,
-					Mode:           "one",
-					PodName:        pi.Metadata.Name,
-				}.String()
+				containers, ok := m.podContainers(expType, pi)
+				if !ok {
+					continue
+				}
+				experiment, err := m.singleExperiment(expType, namespace, pi.Metadata.Name, pi.Metadata.Name, "", containers)
 				if err != nil {
 					return nil, err
 				}
-				experiments[pi.Metadata.Name] = experiment
-			}
-		case ChaosTypeStressMemory:
-			for _, pi := range podsInfo {
-				experiment, err := PodStressMemoryExperiment{
-					Namespace:      namespace,
-					ExperimentName: fmt.Sprintf("%s-%s", ChaosTypeStressMemory, pi.Metadata.Name),
-					Duration:       m.cfg.Havoc.StressMemory.Duration,
-					Workers:        m.cfg.Havoc.StressMemory.Workers,
-					Memory:         m.cfg.Havoc.StressMemory.Memory,
-					Mode:           "one",
-					PodName:        pi.Metadata.Name,
-				}.String()
-				if err != nil {
+				if err := m.addExperiment(experiments, pi.Metadata.Name, experiment); err != nil {
 					return nil, err
 				}
-				experiments[pi.Metadata.Name] = experiment
 			}
 		case ChaosTypeStressGroupMemory:
 			for _, entry := range groupLabels {
+				if podsOptedOut(expType, allPodsInfo[entry.Key]...) {
+					continue
+				}
+				containers, ok := m.podContainers(expType, allPodsInfo[entry.Key]...)
+				if !ok {
+					continue
+				}
 				for _, groupModeValue := range m.cfg.Havoc.StressMemory.GroupPercentage {
 					groupModeValue = maybeFailAll(entry, groupModeValue)
 					sanitizedLabel := sanitizeLabel(entry.Key)
 					sanitizedLabel = fmt.Sprintf("%s-%s-perc", sanitizedLabel, groupModeValue)
-					experiment, err := PodStressMemoryExperiment{
-						Namespace:      namespace,
-						ExperimentName: fmt.Sprintf("%s-%s", ChaosTypeStressGroupMemory, sanitizedLabel),
-						Duration:       m.cfg.Havoc.StressMemory.Duration,
-						Workers:        m.cfg.Havoc.StressMemory.Workers,
-						Memory:         m.cfg.Havoc.StressMemory.Memory,
-						Mode:           "fixed-percent",
-						ModeValue:      groupModeValue,
-						Selector:       entry.Key,
-					}.String()
+					err := m.addExperiment(experiments, sanitizedLabel, PodStressMemoryExperiment{
+						Namespace:           namespace,
+						ExperimentName:      fmt.Sprintf("%s-%s", ChaosTypeStressGroupMemory, sanitizedLabel),
+						Duration:            m.cfg.Havoc.StressMemory.Duration,
+						Workers:             m.cfg.Havoc.StressMemory.Workers,
+						Memory:              m.cfg.Havoc.StressMemory.Memory,
+						Mode:                "fixed-percent",
+						ModeValue:           groupModeValue,
+						Selector:            entry.Key,
+						ExpressionSelectors: m.componentExpressionSelectors(),
+						ContainerNames:      containers,
+					})
 					if err != nil {
 						return nil, err
 					}
-					experiments[sanitizedLabel] = experiment
 				}
 				for _, groupModeValue := range m.cfg.Havoc.StressMemory.GroupFixed {
 					groupModeValue = maybeFailAll(entry, groupModeValue)
 					sanitizedLabel := sanitizeLabel(entry.Key)
 					sanitizedLabel = fmt.Sprintf("%s-%s-fixed", sanitizedLabel, groupModeValue)
-					experiment, err := PodStressMemoryExperiment{
-						Namespace:      namespace,
-						ExperimentName: fmt.Sprintf("%s-%s", ChaosTypeStressGroupMemory, sanitizedLabel),
-						Duration:       m.cfg.Havoc.StressMemory.Duration,
-						Workers:        m.cfg.Havoc.StressMemory.Workers,
-						Memory:         m.cfg.Havoc.StressMemory.Memory,
-						Mode:           "fixed",
-						ModeValue:      groupModeValue,
-						Selector:       entry.Key,
-					}.String()
+					err := m.addExperiment(experiments, sanitizedLabel, PodStressMemoryExperiment{
+						Namespace:           namespace,
+						ExperimentName:      fmt.Sprintf("%s-%s", ChaosTypeStressGroupMemory, sanitizedLabel),
+						Duration:            m.cfg.Havoc.StressMemory.Duration,
+						Workers:             m.cfg.Havoc.StressMemory.Workers,
+						Memory:              m.cfg.Havoc.StressMemory.Memory,
+						Mode:                "fixed",
+						ModeValue:           groupModeValue,
+						Selector:            entry.Key,
+						ExpressionSelectors: m.componentExpressionSelectors(),
+						ContainerNames:      containers,
+					})
 					if err != nil {
 						return nil, err
 					}
-					experiments[sanitizedLabel] = experiment
 				}
 			}
 		case ChaosTypeStressGroupCPU:
 			for _, entry := range groupLabels {
+				if podsOptedOut(expType, allPodsInfo[entry.Key]...) {
+					continue
+				}
+				containers, ok := m.podContainers(expType, allPodsInfo[entry.Key]...)
+				if !ok {
+					continue
+				}
 				for _, groupModeValue := range m.cfg.Havoc.StressCPU.GroupPercentage {
 					groupModeValue = maybeFailAll(entry, groupModeValue)
 					sanitizedLabel := sanitizeLabel(entry.Key)
 					sanitizedLabel = fmt.Sprintf("%s-%s-perc", sanitizedLabel, groupModeValue)
-					experiment, err := PodStressCPUExperiment{
-						Namespace:      namespace,
-						ExperimentName: fmt.Sprintf("%s-%s", ChaosTypeStressGroupCPU, sanitizedLabel),
-						Duration:       m.cfg.Havoc.StressCPU.Duration,
-						Workers:        m.cfg.Havoc.StressCPU.Workers,
-						Load:           m.cfg.Havoc.StressCPU.Load,
-						Mode:           "fixed-percent",
-						ModeValue:      groupModeValue,
-						Selector:       entry.Key,
-					}.String()
+					err := m.addExperiment(experiments, sanitizedLabel, PodStressCPUExperiment{
+						Namespace:           namespace,
+						ExperimentName:      fmt.Sprintf("%s-%s", ChaosTypeStressGroupCPU, sanitizedLabel),
+						Duration:            m.cfg.Havoc.StressCPU.Duration,
+						Workers:             m.cfg.Havoc.StressCPU.Workers,
+						Load:                m.cfg.Havoc.StressCPU.Load,
+						Mode:                "fixed-percent",
+						ModeValue:           groupModeValue,
+						Selector:            entry.Key,
+						ExpressionSelectors: m.componentExpressionSelectors(),
+						ContainerNames:      containers,
+					})
 					if err != nil {
 						return nil, err
 					}
-					experiments[sanitizedLabel] = experiment
 				}
 				for _, groupModeValue := range m.cfg.Havoc.StressCPU.GroupFixed {
 					groupModeValue = maybeFailAll(entry, groupModeValue)
 					sanitizedLabel := sanitizeLabel(entry.Key)
 					sanitizedLabel = fmt.Sprintf("%s-%s-fixed", sanitizedLabel, groupModeValue)
-					experiment, err := PodStressCPUExperiment{
-						Namespace:      namespace,
-						ExperimentName: fmt.Sprintf("%s-%s", ChaosTypeStressGroupCPU, sanitizedLabel),
-						Duration:       m.cfg.Havoc.StressCPU.Duration,
-						Workers:        m.cfg.Havoc.StressCPU.Workers,
-						Load:           m.cfg.Havoc.StressCPU.Load,
-						Mode:           "fixed",
-						ModeValue:      groupModeValue,
-						Selector:       entry.Key,
-					}.String()
+					err := m.addExperiment(experiments, sanitizedLabel, PodStressCPUExperiment{
+						Namespace:           namespace,
+						ExperimentName:      fmt.Sprintf("%s-%s", ChaosTypeStressGroupCPU, sanitizedLabel),
+						Duration:            m.cfg.Havoc.StressCPU.Duration,
+						Workers:             m.cfg.Havoc.StressCPU.Workers,
+						Load:                m.cfg.Havoc.StressCPU.Load,
+						Mode:                "fixed",
+						ModeValue:           groupModeValue,
+						Selector:            entry.Key,
+						ExpressionSelectors: m.componentExpressionSelectors(),
+						ContainerNames:      containers,
+					})
 					if err != nil {
 						return nil, err
 					}
-					experiments[sanitizedLabel] = experiment
 				}
 			}
 		case ChaosTypeGroupFailure:
 			for _, entry := range groupLabels {
+				if podsOptedOut(expType, allPodsInfo[entry.Key]...) {
+					continue
+				}
 				for _, groupModeValue := range m.cfg.Havoc.Failure.GroupPercentage {
 					groupModeValue = maybeFailAll(entry, groupModeValue)
 					sanitizedLabel := sanitizeLabel(entry.Key)
 					sanitizedLabel = fmt.Sprintf("%s-%s-perc", sanitizedLabel, groupModeValue)
-					experiment, err := PodFailureExperiment{
-						Namespace:      namespace,
-						ExperimentName: fmt.Sprintf("%s-%s", ChaosTypeGroupFailure, sanitizedLabel),
-						Duration:       m.cfg.Havoc.Failure.Duration,
-						Mode:           "fixed-percent",
-						ModeValue:      groupModeValue,
-						Selector:       entry.Key,
-					}.String()
+					err := m.addExperiment(experiments, sanitizedLabel, PodFailureExperiment{
+						Namespace:           namespace,
+						ExperimentName:      fmt.Sprintf("%s-%s", ChaosTypeGroupFailure, sanitizedLabel),
+						Duration:            m.cfg.Havoc.Failure.Duration,
+						Mode:                "fixed-percent",
+						ModeValue:           groupModeValue,
+						Selector:            entry.Key,
+						ExpressionSelectors: m.componentExpressionSelectors(),
+					})
 					if err != nil {
 						return nil, err
 					}
-					experiments[sanitizedLabel] = experiment
 				}
 				for _, groupModeValue := range m.cfg.Havoc.Failure.GroupFixed {
 					groupModeValue = maybeFailAll(entry, groupModeValue)
 					sanitizedLabel := sanitizeLabel(entry.Key)
 					sanitizedLabel = fmt.Sprintf("%s-%s-fixed", sanitizedLabel, groupModeValue)
-					experiment, err := PodFailureExperiment{
-						Namespace:      namespace,
-						ExperimentName: fmt.Sprintf("%s-%s", ChaosTypeGroupFailure, sanitizedLabel),
-						Duration:       m.cfg.Havoc.Failure.Duration,
-						Mode:           "fixed",
-						ModeValue:      groupModeValue,
-						Selector:       entry.Key,
-					}.String()
+					err := m.addExperiment(experiments, sanitizedLabel, PodFailureExperiment{
+						Namespace:           namespace,
+						ExperimentName:      fmt.Sprintf("%s-%s", ChaosTypeGroupFailure, sanitizedLabel),
+						Duration:            m.cfg.Havoc.Failure.Duration,
+						Mode:                "fixed",
+						ModeValue:           groupModeValue,
+						Selector:            entry.Key,
+						ExpressionSelectors: m.componentExpressionSelectors(),
+					})
+					if err != nil {
+						return nil, err
+					}
+				}
+			}
+		case ChaosTypeGroupContainerKill:
+			for _, entry := range groupLabels {
+				if podsOptedOut(expType, allPodsInfo[entry.Key]...) {
+					continue
+				}
+				containers, ok := m.podContainers(expType, allPodsInfo[entry.Key]...)
+				if !ok {
+					continue
+				}
+				for _, groupModeValue := range m.cfg.Havoc.ContainerKill.GroupPercentage {
+					groupModeValue = maybeFailAll(entry, groupModeValue)
+					sanitizedLabel := sanitizeLabel(entry.Key)
+					sanitizedLabel = fmt.Sprintf("%s-%s-perc", sanitizedLabel, groupModeValue)
+					err := m.addExperiment(experiments, sanitizedLabel, ContainerKillExperiment{
+						Namespace:           namespace,
+						ExperimentName:      fmt.Sprintf("%s-%s", ChaosTypeGroupContainerKill, sanitizedLabel),
+						Mode:                "fixed-percent",
+						ModeValue:           groupModeValue,
+						Selector:            entry.Key,
+						ExpressionSelectors: m.componentExpressionSelectors(),
+						ContainerNames:      containers,
+					})
+					if err != nil {
+						return nil, err
+					}
+				}
+				for _, groupModeValue := range m.cfg.Havoc.ContainerKill.GroupFixed {
+					groupModeValue = maybeFailAll(entry, groupModeValue)
+					sanitizedLabel := sanitizeLabel(entry.Key)
+					sanitizedLabel = fmt.Sprintf("%s-%s-fixed", sanitizedLabel, groupModeValue)
+					err := m.addExperiment(experiments, sanitizedLabel, ContainerKillExperiment{
+						Namespace:           namespace,
+						ExperimentName:      fmt.Sprintf("%s-%s", ChaosTypeGroupContainerKill, sanitizedLabel),
+						Mode:                "fixed",
+						ModeValue:           groupModeValue,
+						Selector:            entry.Key,
+						ExpressionSelectors: m.componentExpressionSelectors(),
+						ContainerNames:      containers,
+					})
 					if err != nil {
 						return nil, err
 					}
-					experiments[sanitizedLabel] = experiment
 				}
 			}
 		case ChaosTypeGroupLatency:
 			for _, entry := range groupLabels {
+				if podsOptedOut(expType, allPodsInfo[entry.Key]...) {
+					continue
+				}
 				for _, groupModeValue := range m.cfg.Havoc.Latency.GroupPercentage {
 					groupModeValue = maybeFailAll(entry, groupModeValue)
 					sanitizedLabel := sanitizeLabel(entry.Key)
 					sanitizedLabel = fmt.Sprintf("%s-%s-perc", sanitizedLabel, groupModeValue)
-					experiment, err := NetworkChaosExperiment{
-						Namespace:      namespace,
-						ExperimentName: fmt.Sprintf("%s-%s", ChaosTypeGroupLatency, sanitizedLabel),
-						Mode:           "fixed-percent",
-						ModeValue:      groupModeValue,
-						Duration:       m.cfg.Havoc.Latency.Duration,
-						Latency:        m.cfg.Havoc.Latency.Latency,
-						Selector:       entry.Key,
-					}.String()
+					err := m.addExperiment(experiments, sanitizedLabel, NetworkChaosExperiment{
+						Namespace:           namespace,
+						ExperimentName:      fmt.Sprintf("%s-%s", ChaosTypeGroupLatency, sanitizedLabel),
+						Mode:                "fixed-percent",
+						ModeValue:           groupModeValue,
+						Duration:            m.cfg.Havoc.Latency.Duration,
+						Latency:             m.cfg.Havoc.Latency.Latency,
+						Selector:            entry.Key,
+						ExpressionSelectors: m.componentExpressionSelectors(),
+					})
 					if err != nil {
 						return nil, err
 					}
-					experiments[sanitizedLabel] = experiment
 				}
 				for _, groupModeValue := range m.cfg.Havoc.Latency.GroupFixed {
 					groupModeValue = maybeFailAll(entry, groupModeValue)
 					sanitizedLabel := sanitizeLabel(entry.Key)
 					sanitizedLabel = fmt.Sprintf("%s-%s-fixed", sanitizedLabel, groupModeValue)
-					experiment, err := NetworkChaosExperiment{
-						Namespace:      namespace,
-						ExperimentName: fmt.Sprintf("%s-%s", ChaosTypeGroupLatency, sanitizedLabel),
-						Mode:           "fixed",
-						ModeValue:      groupModeValue,
-						Duration:       m.cfg.Havoc.Latency.Duration,
-						Latency:        m.cfg.Havoc.Latency.Latency,
-						Selector:       entry.Key,
-					}.String()
+					err := m.addExperiment(experiments, sanitizedLabel, NetworkChaosExperiment{
+						Namespace:           namespace,
+						ExperimentName:      fmt.Sprintf("%s-%s", ChaosTypeGroupLatency, sanitizedLabel),
+						Mode:                "fixed",
+						ModeValue:           groupModeValue,
+						Duration:            m.cfg.Havoc.Latency.Duration,
+						Latency:             m.cfg.Havoc.Latency.Latency,
+						Selector:            entry.Key,
+						ExpressionSelectors: m.componentExpressionSelectors(),
+					})
 					if err != nil {
 						return nil, err
 					}
-					experiments[sanitizedLabel] = experiment
 				}
 			}
 		}
-		allExperimentsByType[expType] = experiments
 	}
-	return &ChaosSpecs{
-		ExperimentsByType: allExperimentsByType,
-	}, nil
+	return specs, nil
 }
 
 func urlHash(url string) string {
@@ -884,6 +719,9 @@ func urlHash(url string) string {
 
 func sanitizeLabel(label string) string {
 	sanitizedLabel := strings.Replace(label, "'", "", -1)
+	sanitizedLabel = strings.Replace(sanitizedLabel, "{", "", -1)
+	sanitizedLabel = strings.Replace(sanitizedLabel, "}", "", -1)
+	sanitizedLabel = strings.Replace(sanitizedLabel, ", ", "-", -1)
 	sanitizedLabel = strings.Replace(sanitizedLabel, ": ", "-", -1)
 	sanitizedLabel = strings.Replace(sanitizedLabel, ".", "-", -1)
 	sanitizedLabel = strings.Replace(sanitizedLabel, "/", "-", -1)
@@ -1092,22 +930,63 @@ func (m *Controller) GenerateSpecs(ns string) error {
 }
 
 func (m *Controller) generateSpecs(namespace string, podListResponse *PodsListResponse) (*ChaosSpecs, []*PodResponse, error) {
+	csp, noGroup, err := m.buildSpecs(namespace, podListResponse)
+	if err != nil {
+		return nil, nil, err
+	}
+	return csp, noGroup, csp.Dump(m.cfg.Havoc.Dir)
+}
+
+// buildSpecs generates specs for a namespace without writing them
+func (m *Controller) buildSpecs(namespace string, podListResponse *PodsListResponse) (*ChaosSpecs, []*PodResponse, error) {
 	L.Trace().
 		Interface("PodListResponse", podListResponse).
 		Msg("Found pods")
+	var workloads []*Workload
+	if m.cfg.Havoc.WorkloadDiscovery {
+		workloads = m.discoverWorkloads(podListResponse)
+	}
 	all, noGroup, componentLabels, networkLabels, err := m.processPodInfoLo(podListResponse)
 	if err != nil {
 		return nil, nil, err
 	}
-	L.Info().Msg("Processing OpenAPI specs")
-	specs, err := m.ParseOpenAPISpecs()
-	if err != nil {
-		return nil, nil, err
+	noGroup = podsWithoutWorkload(noGroup, workloads)
+	expTypes := m.namespaceExperimentTypes(podListResponse.Namespace)
+	var specs []*OAPISpecData
+	// specs are read from pods, so they are only discovered for groups HTTP experiments are generated for
+	if lo.Contains(expTypes, ChaosTypeHTTP) {
+		L.Info().Msg("Processing OpenAPI specs")
+		mapping, err := m.openAPIMapping(namespace, all, componentLabels)
+		if err != nil {
+			return nil, nil, err
+		}
+		specs, err = m.parseOpenAPISpecs(mapping)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 	L.Info().Msg("Generating chaos experiments")
-	csp, err := m.generate(namespace, specs, all, noGroup, componentLabels, networkLabels)
+	csp, err := m.generate(expTypes, namespace, specs, all, noGroup, componentLabels, networkLabels)
 	if err != nil {
 		return nil, nil, err
 	}
-	return csp, noGroup, csp.Dump(m.cfg.Havoc.Dir)
+	if err := m.generateWorkloadExperiments(csp, namespace, workloads); err != nil {
+		return nil, nil, err
+	}
+	if err := m.generateExtraPartitions(csp, namespace, podListResponse); err != nil {
+		return nil, nil, err
+	}
+	if m.hasTopologyExperiments() {
+		nodes, err := m.GetNodesInfo()
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := m.generateTopologyExperiments(csp, namespace, podListResponse, nodes); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := csp.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return csp, noGroup, nil
 }
@@ -1,27 +1,56 @@
 package havoc
 
 import (
-	"fmt"
 	"os"
-	"strings"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
-	ChaosTypeBlockchainSetHead = "blockchain_rewind_head"
-	ChaosTypeFailure           = "failure"
-	ChaosTypeGroupFailure      = "group-failure"
-	ChaosTypeLatency           = "latency"
-	ChaosTypeGroupLatency      = "group-latency"
-	ChaosTypeStressMemory      = "memory"
-	ChaosTypeStressGroupMemory = "group-memory"
-	ChaosTypeStressCPU         = "cpu"
-	ChaosTypeStressGroupCPU    = "group-cpu"
-	ChaosTypePartitionExternal = "external"
-	ChaosTypePartitionGroup    = "group-partition"
-	ChaosTypeHTTP              = "http"
+	ChaosTypeBlockchainSetHead  = "blockchain_rewind_head"
+	ChaosTypeFailure            = "failure"
+	ChaosTypeGroupFailure       = "group-failure"
+	ChaosTypeLatency            = "latency"
+	ChaosTypeGroupLatency       = "group-latency"
+	ChaosTypeStressMemory       = "memory"
+	ChaosTypeStressGroupMemory  = "group-memory"
+	ChaosTypeStressCPU          = "cpu"
+	ChaosTypeStressGroupCPU     = "group-cpu"
+	ChaosTypePartitionExternal  = "external"
+	ChaosTypePartitionGroup     = "group-partition"
+	ChaosTypeHTTP               = "http"
+	ChaosTypeNodeFailure        = "node-failure"
+	ChaosTypeZoneFailure        = "zone-failure"
+	ChaosTypeNodePartition      = "node-partition"
+	ChaosTypeZonePartition      = "zone-partition"
+	ChaosTypeContainerKill      = "container-kill"
+	ChaosTypeGroupContainerKill = "group-container-kill"
+)
+
+var (
+	// KnownExperimentTypes are all experiment types havoc can generate, experiment dirs are named after them
+	KnownExperimentTypes = []string{
+		ChaosTypeBlockchainSetHead,
+		ChaosTypeFailure,
+		ChaosTypeGroupFailure,
+		ChaosTypeLatency,
+		ChaosTypeGroupLatency,
+		ChaosTypeStressMemory,
+		ChaosTypeStressGroupMemory,
+		ChaosTypeStressCPU,
+		ChaosTypeStressGroupCPU,
+		ChaosTypePartitionExternal,
+		ChaosTypePartitionGroup,
+		ChaosTypeHTTP,
+		ChaosTypeNodeFailure,
+		ChaosTypeZoneFailure,
+		ChaosTypeNodePartition,
+		ChaosTypeZonePartition,
+		ChaosTypeContainerKill,
+		ChaosTypeGroupContainerKill,
+	}
 )
 
 var (
@@ -52,29 +81,11 @@ func InitDefaultLogging() {
 
 type ChaosSpecs struct {
 	ExperimentsByType map[string]map[string]string
+	// ObjectsByType are Chaos Mesh v1alpha1 objects experiments are serialized from, custom kinds have no objects
+	ObjectsByType map[string]map[string]client.Object
 }
 
 func (m *ChaosSpecs) Dump(dir string) error {
-	if err := os.RemoveAll(dir); err != nil {
-		return err
-	}
-	if err := os.Mkdir(dir, os.ModePerm); err != nil {
-		return err
-	}
 	L.Info().Str("Dir", dir).Msg("Writing experiments to a dir")
-	for expType := range m.ExperimentsByType {
-		if len(m.ExperimentsByType[expType]) == 0 {
-			continue
-		}
-		if err := os.Mkdir(fmt.Sprintf("%s/%s", dir, expType), os.ModePerm); err != nil {
-			return err
-		}
-		for expName, expBody := range m.ExperimentsByType[expType] {
-			fname := strings.ToLower(fmt.Sprintf("%s/%s/%s-%s.yaml", dir, expType, expType, expName))
-			if err := os.WriteFile(fname, []byte(expBody), os.ModePerm); err != nil {
-				return err
-			}
-		}
-	}
-	return nil
+	return writeFiles(dir, m.Files())
 }
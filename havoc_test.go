@@ -1,13 +1,23 @@
 package havoc
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
+	"text/template"
+	"time"
 
+	"github.com/chaos-mesh/chaos-mesh/api/v1alpha1"
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 var (
@@ -106,6 +116,15 @@ func TestSmokeParsingGenerating(t *testing.T) {
 			for i := range snapshotData {
 				// Replace snapshot dir name to match it with expected results path
 				snapshotData[i].Path = strings.ReplaceAll(snapshotData[i].Path, SnapshotDir, ResultsDir)
+				// manifests are compared as objects, so field order and quoting of serialized objects don't matter
+				expected, err := decodeExperiment(string(snapshotData[i].CRDBytes))
+				require.NoError(t, err)
+				generated, err := decodeExperiment(string(generatedData[i].CRDBytes))
+				require.NoError(t, err)
+				require.Equal(t, expected, generated, snapshotData[i].Path)
+				if expected != nil {
+					snapshotData[i].CRDBytes, generatedData[i].CRDBytes = nil, nil
+				}
 				require.Equal(t, snapshotData[i], generatedData[i])
 			}
 		})
@@ -132,3 +151,763 @@ func TestManualRun(t *testing.T) {
 	err = m.Run()
 	require.NoError(t, err)
 }
+
+func TestSmokeConfigDefaults(t *testing.T) {
+	defaults := DefaultConfig()
+	for i := 0; i < 2; i++ {
+		cfg, err := ReadConfig("havoc.toml")
+		require.NoError(t, err)
+		require.Equal(t, []string{"1"}, cfg.Havoc.ContainerKill.GroupFixed)
+		cfg.Havoc.Failure.GroupFixed[0] = "changed"
+		cfg.Havoc.IgnoreGroupLabels[0] = "changed"
+	}
+	require.Equal(t, []string{"1", "2", "3"}, DefaultGroupFixed)
+	require.Equal(t, defaults, DefaultConfig())
+}
+
+func TestSmokePlan(t *testing.T) {
+	m, plr := setup(t, "deployment_single_pod.json", "", "single_pod")
+	experiments, err := m.ReadExperimentsFromDir(AllExperimentTypes, filepath.Join(SnapshotDir, "single_pod"))
+	require.NoError(t, err)
+	p, err := newPlan(MonkeyModeSeq, 3*time.Minute, 30*time.Second, experiments)
+	require.NoError(t, err)
+	require.Len(t, p.Experiments, 4)
+	require.Equal(t, 6*time.Minute, p.ExpectedDuration())
+	// monkey checks its duration before cooldown, the third experiment starts at 2m30s
+	require.False(t, p.Experiments[2].Skipped)
+	require.True(t, p.Experiments[3].Skipped)
+	sel, target, _, err := parseExperimentSelectors(p.Experiments[1].Experiment)
+	require.NoError(t, err)
+	require.Equal(t, "one", sel.Mode)
+	require.Equal(t, []string{Namespace}, sel.Selector.Namespaces)
+	require.Equal(t, map[string]string{"metadata.name": "my-single-app"}, sel.Selector.FieldSelectors)
+	require.NotNil(t, target)
+
+	// dry-run plans experiments in memory in the same order monkey reads them from dir
+	csp, _, err := m.generateSpecs(Namespace, plr)
+	require.NoError(t, err)
+	written, err := m.ReadAllExperiments(m.cfg.Havoc.Dir)
+	require.NoError(t, err)
+	planned, err := m.specsExperiments(map[string]*ChaosSpecs{Namespace: csp})
+	require.NoError(t, err)
+	require.Equal(t, written, planned)
+}
+
+func TestSmokePlanMatchesRun(t *testing.T) {
+	const (
+		expDuration    = 200 * time.Millisecond
+		cooldown       = 100 * time.Millisecond
+		monkeyDuration = 600 * time.Millisecond
+	)
+	experiments := make([]*NamedExperiment, 0)
+	for i := 0; i < 4; i++ {
+		exp := &NamedExperiment{
+			Name:     fmt.Sprintf("exp-%d", i),
+			CRDBytes: []byte(fmt.Sprintf("spec:\n  duration: %s\n", expDuration)),
+		}
+		exp.Kind = ChaosTypeFailure
+		experiments = append(experiments, exp)
+	}
+	p, err := newPlan(MonkeyModeSeq, monkeyDuration, cooldown, experiments)
+	require.NoError(t, err)
+	planned := make([]string, 0)
+	for _, pe := range p.Experiments {
+		if !pe.Skipped {
+			planned = append(planned, pe.Experiment.Name)
+		}
+	}
+	// the second experiment ends at 500ms, before monkey duration, so the third one is started
+	require.Equal(t, []string{"exp-0", "exp-1", "exp-2"}, planned)
+
+	m, _ := setup(t, "deployment_single_pod.json", "", "single_pod")
+	applied := make([]string, 0)
+	m.apply = func(exp *NamedExperiment) error {
+		applied = append(applied, exp.Name)
+		time.Sleep(expDuration)
+		return nil
+	}
+	m.ctx, m.cancel = context.WithTimeout(context.Background(), monkeyDuration)
+	defer m.cancel()
+	m.wg.Add(1)
+	require.NoError(t, m.runSeq(experiments, cooldown))
+	require.Equal(t, planned, applied)
+}
+
+func TestSmokeCLIFormatWithDiff(t *testing.T) {
+	for _, flag := range []string{"--diff", "--merge"} {
+		err := RunCLI([]string{"havoc", "generate", flag, "--format", OutputFormatHelm, Namespace})
+		require.EqualError(t, err, ErrFormatWithDiff)
+	}
+}
+
+func TestSmokeTargets(t *testing.T) {
+	plr, err := ReadPodsListResponse(filepath.Join(DeploymentsDir, "deployment_crib_block_rewind.json"))
+	require.NoError(t, err)
+	exp, err := NewNamedExperiment(filepath.Join(SnapshotDir, "all", ChaosTypeGroupFailure, "group-failure-havoc-component-group-node-2-fixed.yaml"))
+	require.NoError(t, err)
+	selected, target, err := ExperimentTargets(exp, plr)
+	require.NoError(t, err)
+	require.Nil(t, target)
+	require.Len(t, selected.Candidates, 5)
+	require.Equal(t, 2, selected.Picked)
+
+	exp, err = NewNamedExperiment(filepath.Join(SnapshotDir, "all", ChaosTypePartitionGroup, "group-partition-havoc-network-group-1-to-havoc-network-group-2-100-perc.yaml"))
+	require.NoError(t, err)
+	selected, target, err = ExperimentTargets(exp, plr)
+	require.NoError(t, err)
+	require.Len(t, selected.Candidates, 3)
+	require.Equal(t, 3, selected.Picked)
+	require.Len(t, target.Candidates, 2)
+	require.Equal(t, 2, target.Picked)
+
+	_, err = pickedByMode(SelectorModeFixedPercent, "150", 3)
+	require.Error(t, err)
+}
+
+func TestSmokeMultiNamespace(t *testing.T) {
+	m, core := setup(t, "deployment_crib_block_rewind.json", "", "multi_namespace")
+	chains, err := ReadPodsListResponse(filepath.Join(DeploymentsDir, "deployment_crib_block_rewind.json"))
+	require.NoError(t, err)
+	m.cfg.Havoc.ExperimentTypes = []string{ChaosTypeGroupFailure, ChaosTypePartitionGroup}
+	m.cfg.Havoc.NetworkPartition.CrossNamespace = true
+	specs, err := m.generateSpecsForNamespaces(map[string]*PodsListResponse{"core": core, "chains": chains})
+	require.NoError(t, err)
+	require.Len(t, specs, 2)
+
+	dirs, err := experimentDirs(m.cfg.Havoc.Dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(m.cfg.Havoc.Dir, "chains"), filepath.Join(m.cfg.Havoc.Dir, "core")}, dirs)
+
+	// namespaces are sorted, the first one in a pair is a source namespace
+	exp, err := NewNamedExperiment(filepath.Join(m.cfg.Havoc.Dir, "chains", ChaosTypePartitionGroup, "group-partition-chains-havoc-network-group-1-to-core-havoc-network-group-2-100-perc.yaml"))
+	require.NoError(t, err)
+	sel, target, _, err := parseExperimentSelectors(exp)
+	require.NoError(t, err)
+	require.Equal(t, []string{"chains"}, sel.Selector.Namespaces)
+	require.Equal(t, []string{"core"}, target.Selector.Namespaces)
+
+	all, err := m.ReadAllExperiments(m.cfg.Havoc.Dir)
+	require.NoError(t, err)
+	types := lo.Uniq(lo.Map(all, func(item *NamedExperiment, _ int) string {
+		return filepath.Base(filepath.Dir(item.Path))
+	}))
+	require.Equal(t, []string{ChaosTypeGroupFailure, ChaosTypePartitionGroup}, types)
+
+	// dry-run plans experiments in memory in the same order monkey reads them from dir
+	planned, err := m.specsExperiments(specs)
+	require.NoError(t, err)
+	require.Equal(t, all, planned)
+}
+
+func TestSmokePartitions(t *testing.T) {
+	m, plr := setup(t, "deployment_crib_block_rewind.json", "", "partitions")
+	m.cfg.Havoc.ExperimentTypes = []string{ChaosTypePartitionGroup}
+	m.cfg.Havoc.NetworkPartition.Isolate = true
+	m.cfg.Havoc.NetworkPartition.Direction = PartitionDirectionBoth
+	csp, _, err := m.generateSpecs(Namespace, plr)
+	require.NoError(t, err)
+	experiments := csp.ExperimentsByType[ChaosTypePartitionGroup]
+
+	var isolation struct {
+		Spec struct {
+			Mode      string `yaml:"mode"`
+			Direction string `yaml:"direction"`
+			Target    struct {
+				Mode     string `yaml:"mode"`
+				Selector struct {
+					ExpressionSelectors []struct {
+						Key      string   `yaml:"key"`
+						Operator string   `yaml:"operator"`
+						Values   []string `yaml:"values"`
+					} `yaml:"expressionSelectors"`
+				} `yaml:"selector"`
+			} `yaml:"target"`
+		} `yaml:"spec"`
+	}
+	require.Contains(t, experiments, "havoc-network-group-1-to-all-100-perc")
+	err = yaml.Unmarshal([]byte(experiments["havoc-network-group-1-to-all-100-perc"]), &isolation)
+	require.NoError(t, err)
+	require.Equal(t, PartitionDirectionBoth, isolation.Spec.Direction)
+	require.Equal(t, SelectorModeFixedPercent, isolation.Spec.Mode)
+	require.Equal(t, SelectorModeAll, isolation.Spec.Target.Mode)
+	require.Len(t, isolation.Spec.Target.Selector.ExpressionSelectors, 1)
+	require.Equal(t, "NotIn", isolation.Spec.Target.Selector.ExpressionSelectors[0].Operator)
+	require.Equal(t, []string{"1"}, isolation.Spec.Target.Selector.ExpressionSelectors[0].Values)
+
+	services := map[string]map[string]string{"geth": {"app": "geth", "release": "app"}}
+	svcExperiments := (&ChaosSpecs{}).addType(ChaosTypePartitionGroup)
+	err = m.generateServicePartitions(svcExperiments, Namespace, []string{"'havoc-network-group': '1'"}, services)
+	require.NoError(t, err)
+	exp := &NamedExperiment{CRDBytes: []byte(svcExperiments.manifests["havoc-network-group-1-to-svc-geth-100-perc"])}
+	// generation prunes pod labels, reading pods again
+	plr, err = ReadPodsListResponse(filepath.Join(DeploymentsDir, "deployment_crib_block_rewind.json"))
+	require.NoError(t, err)
+	selected, target, err := ExperimentTargets(exp, plr)
+	require.NoError(t, err)
+	require.Len(t, selected.Candidates, 3)
+	require.Len(t, target.Candidates, 2)
+	require.Equal(t, 2, target.Picked)
+}
+
+// readWorkloads reads workloads that own fixture pods, the same way GetPodsInfo attaches them
+func readWorkloads(t *testing.T, plr *PodsListResponse) {
+	d, err := os.ReadFile(filepath.Join(DeploymentsDir, "workloads_owners.json"))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(d, &plr.Workloads))
+}
+
+func TestSmokeWorkloads(t *testing.T) {
+	m, plr := setup(t, "deployment_workloads.json", "", "workloads")
+	m.cfg.Havoc.ExperimentTypes = []string{ChaosTypeFailure, ChaosTypeGroupFailure}
+	m.cfg.Havoc.WorkloadDiscovery = true
+	readWorkloads(t, plr)
+	workloads := m.discoverWorkloads(plr)
+	require.Equal(t, []string{"api", "node-exporter", "postgres"}, lo.Map(workloads, func(item *Workload, _ int) string {
+		return item.Name
+	}))
+	require.Equal(t, WorkloadKindDeployment, workloads[0].Kind)
+	require.Equal(t, map[string]string{"app": "api"}, workloads[0].Selector)
+	require.Equal(t, map[string]string{"app": "postgres"}, workloads[2].Selector)
+
+	csp, _, err := m.generateSpecs(Namespace, plr)
+	require.NoError(t, err)
+	experiments := csp.ExperimentsByType[ChaosTypeFailure]
+	require.ElementsMatch(t, []string{"deployment-api", "statefulset-postgres", "daemonset-node-exporter", "debug"}, lo.Keys(experiments))
+
+	exp := &NamedExperiment{CRDBytes: []byte(experiments["statefulset-postgres"])}
+	plr, err = ReadPodsListResponse(filepath.Join(DeploymentsDir, "deployment_workloads.json"))
+	require.NoError(t, err)
+	selected, _, err := ExperimentTargets(exp, plr)
+	require.NoError(t, err)
+	require.Equal(t, []string{"/postgres-0", "/postgres-1"}, selected.Names())
+	require.Equal(t, 1, selected.Picked)
+}
+
+func TestSmokeWorkloadsRollout(t *testing.T) {
+	m, plr := setup(t, "deployment_workloads_rollout.json", "", "workloads_rollout")
+	m.cfg.Havoc.ExperimentTypes = []string{ChaosTypeFailure}
+	m.cfg.Havoc.WorkloadDiscovery = true
+	readWorkloads(t, plr)
+	// experiments are generated before rollout, all pods have the previous revision labels
+	plr.Items = lo.Filter(plr.Items, func(item *PodResponse, _ int) bool {
+		return item.Metadata.Labels["version"] == "v1"
+	})
+	workloads := m.discoverWorkloads(plr)
+	require.Len(t, workloads, 1)
+	require.Equal(t, map[string]string{"app": "api"}, workloads[0].Selector)
+
+	csp, _, err := m.buildSpecs(Namespace, plr)
+	require.NoError(t, err)
+	exp := &NamedExperiment{CRDBytes: []byte(csp.ExperimentsByType[ChaosTypeFailure]["deployment-api"])}
+	plr, err = ReadPodsListResponse(filepath.Join(DeploymentsDir, "deployment_workloads_rollout.json"))
+	require.NoError(t, err)
+	selected, _, err := ExperimentTargets(exp, plr)
+	require.NoError(t, err)
+	require.Equal(t, []string{"/api-7d9f8c6b5d-2xkqp", "/api-7d9f8c6b5d-9fjwl", "/api-5c8b9d7f6e-k4m2n"}, selected.Names())
+}
+
+func TestSmokeCompositeGroups(t *testing.T) {
+	m, plr := setup(t, "deployment_crib_block_rewind.json", "", "composite_groups")
+	m.cfg.Havoc.ExperimentTypes = []string{ChaosTypeGroupFailure}
+	m.cfg.Havoc.ComponentLabelKeys = []string{"app", "havoc-component-group"}
+	m.cfg.Havoc.ComponentLabelExpressions = []*LabelExpression{
+		{Key: "instance", Operator: LabelOperatorExists},
+		{Key: "instance", Operator: LabelOperatorNotIn, Values: []string{"node-6"}},
+	}
+	require.Empty(t, m.cfg.Validate())
+	csp, _, err := m.generateSpecs(Namespace, plr)
+	require.NoError(t, err)
+	experiments := csp.ExperimentsByType[ChaosTypeGroupFailure]
+	require.ElementsMatch(t, []string{
+		"app-app-db-havoc-component-group-db-1-fixed",
+		"app-app-db-havoc-component-group-db-2-fixed",
+		"app-app-db-havoc-component-group-db-3-fixed",
+		"app-app-havoc-component-group-node-1-fixed",
+		"app-app-havoc-component-group-node-2-fixed",
+		"app-app-havoc-component-group-node-3-fixed",
+	}, lo.Keys(experiments))
+
+	exp := &NamedExperiment{CRDBytes: []byte(experiments["app-app-havoc-component-group-node-3-fixed"])}
+	plr, err = ReadPodsListResponse(filepath.Join(DeploymentsDir, "deployment_crib_block_rewind.json"))
+	require.NoError(t, err)
+	selected, _, err := ExperimentTargets(exp, plr)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"/app-node-2-596bb765d6-kph9d",
+		"/app-node-3-6f554cc8b6-g5vk9",
+		"/app-node-4-cf9977d9c-4gt28",
+		"/app-node-5-d557ccf49-s2ffs",
+	}, selected.Names())
+	require.Equal(t, 3, selected.Picked)
+
+	m.cfg.Havoc.ComponentLabelExpressions = []*LabelExpression{{Key: "instance", Operator: "Matches"}}
+	require.Len(t, m.cfg.Validate(), 1)
+
+	var reqs []struct {
+		Key    string
+		Values []string
+	}
+	quoted := flowExpressionSelectors([]*LabelExpression{{Key: "owner's", Operator: LabelOperatorIn, Values: []string{"o'brien"}}})
+	require.NoError(t, yaml.Unmarshal([]byte(quoted), &reqs))
+	require.Equal(t, "owner's", reqs[0].Key)
+	require.Equal(t, []string{"o'brien"}, reqs[0].Values)
+}
+
+func TestSmokeTopology(t *testing.T) {
+	m, plr := setup(t, "deployment_topology.json", "", "topology")
+	m.cfg.Havoc.Topology.Zones = []string{"us-east-1a", "us-east-1b"}
+	d, err := os.ReadFile(filepath.Join(DeploymentsDir, "nodes_topology.json"))
+	require.NoError(t, err)
+	var nlr *NodesListResponse
+	require.NoError(t, json.Unmarshal(d, &nlr))
+
+	csp := &ChaosSpecs{ExperimentsByType: make(map[string]map[string]string)}
+	for _, expType := range TopologyExperimentTypes {
+		csp.ExperimentsByType[expType] = make(map[string]string)
+	}
+	require.NoError(t, m.generateTopologyExperiments(csp, Namespace, plr, nlr))
+	require.Len(t, csp.ExperimentsByType[ChaosTypeNodeFailure], 4)
+	require.Len(t, csp.ExperimentsByType[ChaosTypeNodePartition], 4)
+	require.ElementsMatch(t, []string{"us-east-1a", "us-east-1b"}, lo.Keys(csp.ExperimentsByType[ChaosTypeZoneFailure]))
+	require.ElementsMatch(t, []string{"us-east-1a", "us-east-1b"}, lo.Keys(csp.ExperimentsByType[ChaosTypeZonePartition]))
+
+	zoneFailure := &NamedExperiment{CRDBytes: []byte(csp.ExperimentsByType[ChaosTypeZoneFailure]["us-east-1a"])}
+	selected, _, err := ExperimentTargets(zoneFailure, plr)
+	require.NoError(t, err)
+	require.Equal(t, []string{"/api-0", "/api-1"}, selected.Names())
+	require.Equal(t, 2, selected.Picked)
+
+	zonePartition := &NamedExperiment{CRDBytes: []byte(csp.ExperimentsByType[ChaosTypeZonePartition]["us-east-1a"])}
+	selected, target, err := ExperimentTargets(zonePartition, plr)
+	require.NoError(t, err)
+	require.Equal(t, []string{"/api-0", "/api-1"}, selected.Names())
+	require.Equal(t, []string{"/db-0", "/db-1"}, target.Names())
+
+	nodeFailure := &NamedExperiment{CRDBytes: []byte(csp.ExperimentsByType[ChaosTypeNodeFailure]["ip-10-0-2-10-ec2-internal"])}
+	selected, _, err = ExperimentTargets(nodeFailure, plr)
+	require.NoError(t, err)
+	require.Equal(t, []string{"/db-0"}, selected.Names())
+}
+
+func TestSmokeContainers(t *testing.T) {
+	m, plr := setup(t, "deployment_containers.json", "", "containers")
+	m.cfg.Havoc.ExperimentTypes = []string{ChaosTypeStressCPU, ChaosTypeStressGroupCPU, ChaosTypeContainerKill, ChaosTypeGroupContainerKill}
+	m.cfg.Havoc.StressCPU.Containers = &ContainerFilter{Exclude: []string{"istio-*", "log-shipper"}}
+	m.cfg.Havoc.ContainerKill.Containers = &ContainerFilter{Include: []string{"istio-proxy"}}
+	csp, _, err := m.generateSpecs(Namespace, plr)
+	require.NoError(t, err)
+
+	var spec struct {
+		Spec struct {
+			Action         string   `yaml:"action"`
+			ContainerNames []string `yaml:"containerNames"`
+		} `yaml:"spec"`
+	}
+	// proxy pod has only excluded containers
+	require.ElementsMatch(t, []string{"worker-0"}, lo.Keys(csp.ExperimentsByType[ChaosTypeStressCPU]))
+	require.NoError(t, yaml.Unmarshal([]byte(csp.ExperimentsByType[ChaosTypeStressCPU]["worker-0"]), &spec))
+	require.Equal(t, []string{"worker"}, spec.Spec.ContainerNames)
+	require.NoError(t, yaml.Unmarshal([]byte(csp.ExperimentsByType[ChaosTypeStressGroupCPU]["havoc-component-group-api-1-fixed"]), &spec))
+	require.Equal(t, []string{"api"}, spec.Spec.ContainerNames)
+
+	require.ElementsMatch(t, []string{"worker-0", "proxy-0"}, lo.Keys(csp.ExperimentsByType[ChaosTypeContainerKill]))
+	require.NoError(t, yaml.Unmarshal([]byte(csp.ExperimentsByType[ChaosTypeGroupContainerKill]["havoc-component-group-api-2-fixed"]), &spec))
+	require.Equal(t, "container-kill", spec.Spec.Action)
+	require.Equal(t, []string{"istio-proxy"}, spec.Spec.ContainerNames)
+}
+
+func TestSmokeOptOut(t *testing.T) {
+	m, plr := setup(t, "deployment_crib_block_rewind.json", "", "opt_out")
+	m.cfg.Havoc.ExperimentTypes = []string{ChaosTypeFailure, ChaosTypeLatency, ChaosTypeStressCPU, ChaosTypeGroupFailure, ChaosTypeGroupLatency, ChaosTypePartitionGroup}
+	plr.Namespace = &NamespaceResponse{}
+	plr.Namespace.Metadata.Name = Namespace
+	plr.Namespace.Metadata.Annotations = map[string]string{OptOutKey: ChaosTypeStressCPU}
+	for _, p := range plr.Items {
+		switch p.Metadata.Name {
+		case "app-node-2-596bb765d6-kph9d":
+			p.Metadata.Annotations = map[string]string{OptOutKey: "group-failure, group-partition"}
+		case "mockserver-7cb865999c-qwdt9":
+			p.Metadata.Labels[OptInKey] = ChaosTypeLatency
+		}
+	}
+	csp, _, err := m.generateSpecs(Namespace, plr)
+	require.NoError(t, err)
+	require.NotContains(t, csp.ExperimentsByType, ChaosTypeStressCPU)
+	require.NotContains(t, csp.ExperimentsByType[ChaosTypeFailure], "mockserver-7cb865999c-qwdt9")
+	require.Contains(t, csp.ExperimentsByType[ChaosTypeLatency], "mockserver-7cb865999c-qwdt9")
+	require.NotContains(t, csp.ExperimentsByType[ChaosTypeGroupFailure], "havoc-component-group-node-1-fixed")
+	require.Contains(t, csp.ExperimentsByType[ChaosTypeGroupFailure], "havoc-component-group-db-1-fixed")
+	require.Contains(t, csp.ExperimentsByType[ChaosTypeGroupLatency], "havoc-component-group-node-1-fixed")
+	for k := range csp.ExperimentsByType[ChaosTypePartitionGroup] {
+		require.NotContains(t, k, "havoc-network-group-2")
+	}
+	require.NotEmpty(t, csp.ExperimentsByType[ChaosTypePartitionGroup])
+
+	require.True(t, optedOut(ChaosTypeFailure, map[string]string{OptOutKey: "all"}, nil))
+	require.True(t, optedOut(ChaosTypeFailure, map[string]string{OptOutKey: "latency.failure"}, nil))
+	require.False(t, optedOut(ChaosTypeFailure, map[string]string{OptOutKey: "failure"}, map[string]string{OptOutKey: "latency"}))
+}
+
+func TestSmokeOpenAPIDiscovery(t *testing.T) {
+	m, plr := setup(t, "deployment_openapi.json", "", "openapi")
+	m.cfg.Havoc.ExperimentTypes = []string{ChaosTypeHTTP}
+	m.cfg.Havoc.OpenAPI.Mapping = map[string]*OpenApiSpecInfo{
+		"shop": {SpecToPortMappings: []*SpecToPort{{Path: filepath.Join(OAPISpecs, "petshop.yaml"), PortName: "web"}}},
+	}
+	csp, _, err := m.generateSpecs(Namespace, plr)
+	require.NoError(t, err)
+	experiments := csp.ExperimentsByType[ChaosTypeHTTP]
+	require.Len(t, experiments, 6)
+
+	var spec struct {
+		Spec struct {
+			Port   int64  `yaml:"port"`
+			Method string `yaml:"method"`
+			Path   string `yaml:"path"`
+		} `yaml:"spec"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte(experiments["havoc-component-group-api--pets-GET"]), &spec))
+	require.Equal(t, int64(8080), spec.Spec.Port)
+	require.Contains(t, experiments, "havoc-component-group-shop--pets-petId-GET")
+	require.NoError(t, yaml.Unmarshal([]byte(experiments["havoc-component-group-shop--pets-petId-GET"]), &spec))
+	require.Equal(t, int64(9090), spec.Spec.Port)
+	require.Equal(t, "/pets/*", spec.Spec.Path)
+
+	// broken annotations of groups that don't get HTTP experiments are never read
+	for _, p := range plr.Items {
+		if p.Metadata.Annotations != nil {
+			p.Metadata.Annotations[OpenAPIAnnotationKey] = "/specs/api.yaml"
+		}
+	}
+	m.cfg.Havoc.ExperimentTypes = []string{ChaosTypeFailure}
+	_, _, err = m.buildSpecs(Namespace, plr)
+	require.NoError(t, err)
+	m.cfg.Havoc.ExperimentTypes = []string{ChaosTypeHTTP}
+	_, _, err = m.buildSpecs(Namespace, plr)
+	require.Error(t, err)
+	for _, p := range plr.Items {
+		if p.Metadata.Annotations != nil {
+			p.Metadata.Annotations[OptOutKey] = ChaosTypeHTTP
+		}
+	}
+	csp, _, err = m.buildSpecs(Namespace, plr)
+	require.NoError(t, err)
+	require.NotContains(t, csp.ExperimentsByType[ChaosTypeHTTP], "havoc-component-group-api--pets-GET")
+
+	_, _, err = parseOpenAPIReference("/specs/api.yaml")
+	require.Error(t, err)
+	path, port, err := parseOpenAPIReference("configmap:specs/api.yaml:6688")
+	require.NoError(t, err)
+	require.Equal(t, "configmap:specs/api.yaml", path)
+	require.Equal(t, "6688", port)
+}
+
+func TestSmokeDiffMerge(t *testing.T) {
+	m, plr := setup(t, "deployment_single_group.json", "", "diff")
+	_, _, err := m.generateSpecs(Namespace, plr)
+	require.NoError(t, err)
+	csp, _, err := m.buildSpecs(Namespace, plr)
+	require.NoError(t, err)
+	files := csp.Files()
+
+	d, err := DiffFiles(m.cfg.Havoc.Dir, files)
+	require.NoError(t, err)
+	require.True(t, d.Empty())
+
+	paths := lo.Keys(files)
+	sort.Strings(paths)
+	changed, handEdited, removed, marked := paths[0], paths[1], paths[2], paths[3]
+	handEditedBody := "# " + HandMaintainedKey + "\n" + files[handEdited]
+	require.NoError(t, os.WriteFile(filepath.Join(m.cfg.Havoc.Dir, handEdited), []byte(handEditedBody), os.ModePerm))
+	handOnly := filepath.ToSlash(filepath.Join(filepath.Dir(changed), "custom-experiment.yaml"))
+	require.NoError(t, os.WriteFile(filepath.Join(m.cfg.Havoc.Dir, handOnly), []byte("\n# "+HandMaintainedKey+"\nkind: PodChaos\n"), os.ModePerm))
+	// marker is only respected in leading comments, not anywhere in the manifest
+	markedBody := files[marked] + "# " + HandMaintainedKey + "\n"
+	require.NoError(t, os.WriteFile(filepath.Join(m.cfg.Havoc.Dir, marked), []byte(markedBody), os.ModePerm))
+	// files outside of experiment type dirs are not experiments
+	notExperiments := []string{KustomizationFileName, "Chart.yaml", "templates/failure.yaml"}
+	require.NoError(t, os.MkdirAll(filepath.Join(m.cfg.Havoc.Dir, "templates"), os.ModePerm))
+	for _, f := range notExperiments {
+		require.NoError(t, os.WriteFile(filepath.Join(m.cfg.Havoc.Dir, f), []byte("kind: Kustomization\n"), os.ModePerm))
+	}
+	files[changed] = strings.Replace(files[changed], "mode: ", "mode:  ", 1)
+	delete(files, removed)
+	files["failure/failure-new.yaml"] = "kind: PodChaos\n"
+
+	d, err = DiffFiles(m.cfg.Havoc.Dir, files)
+	require.NoError(t, err)
+	require.Equal(t, []string{"failure/failure-new.yaml"}, d.Added)
+	require.Equal(t, []string{removed}, d.Removed)
+	expectedChanged := []string{changed, marked}
+	sort.Strings(expectedChanged)
+	require.Equal(t, expectedChanged, d.Changed)
+	expectedKept := []string{handOnly, handEdited}
+	sort.Strings(expectedKept)
+	require.Equal(t, expectedKept, d.Kept)
+	require.Contains(t, d.Unified[changed], "--- a/"+changed)
+	require.Contains(t, d.Unified[changed], "+  mode:  ")
+
+	_, err = MergeFiles(m.cfg.Havoc.Dir, files)
+	require.NoError(t, err)
+	merged, err := readSpecFiles(m.cfg.Havoc.Dir)
+	require.NoError(t, err)
+	require.NotContains(t, merged, removed)
+	require.Equal(t, files[changed], merged[changed])
+	require.Equal(t, files[marked], merged[marked])
+	require.Equal(t, handEditedBody, merged[handEdited])
+	require.Contains(t, merged, handOnly)
+	require.Contains(t, merged, "failure/failure-new.yaml")
+	for _, f := range notExperiments {
+		require.NotContains(t, merged, f)
+		require.FileExists(t, filepath.Join(m.cfg.Havoc.Dir, f))
+	}
+}
+
+func TestSmokeOutputFormats(t *testing.T) {
+	m, plr := setup(t, "deployment_crib_block_rewind.json", "crib-all.toml", "all")
+	csp, _, err := m.buildSpecs(Namespace, plr)
+	require.NoError(t, err)
+	files := csp.Files()
+	groupFailure := "group-failure/group-failure-havoc-component-group-blockchain-1-fixed.yaml"
+	require.Contains(t, files, groupFailure)
+
+	_, err = formatFiles("xml", files)
+	require.Error(t, err)
+
+	out, err := formatFiles(OutputFormatYAML, files)
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+	docs := strings.Split(out[YAMLStreamFileName], "---\n")
+	require.Len(t, docs, len(files)-len(csp.ExperimentsByType[ChaosTypeBlockchainSetHead]))
+	var crd CRD
+	require.NoError(t, yaml.Unmarshal([]byte(docs[0]), &crd))
+	require.NotEmpty(t, crd.Metadata.Name)
+
+	out, err = formatFiles(OutputFormatKustomize, files)
+	require.NoError(t, err)
+	require.Contains(t, out[KustomizationFileName], "  - group-failure\n")
+	require.NotContains(t, out[KustomizationFileName], ChaosTypeBlockchainSetHead)
+	require.Contains(t, out["group-failure/"+KustomizationFileName], "  - group-failure-havoc-component-group-blockchain-1-fixed.yaml\n")
+
+	out, err = formatFiles(OutputFormatHelm, files)
+	require.NoError(t, err)
+	require.Contains(t, out, HelmChartFileName)
+	var values struct {
+		Experiments map[string]*helmExperimentValues `yaml:"experiments"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte(out["values.yaml"]), &values))
+	require.Equal(t, &helmExperimentValues{Enabled: true, Duration: "10s", Mode: "fixed", Value: "1"}, values.Experiments["group-failure-havoc-component-group-blockchain-1-fixed"])
+	tpl := out["templates/"+groupFailure]
+	require.Contains(t, tpl, `{{- $e := index .Values.experiments "group-failure-havoc-component-group-blockchain-1-fixed" }}`)
+	require.Contains(t, tpl, "  duration: '{{ $e.duration }}'")
+	require.Contains(t, tpl, "  value: '{{ $e.value }}'")
+	require.NotContains(t, tpl, "duration: 10s")
+	var rendered strings.Builder
+	var chartValues map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(out["values.yaml"]), &chartValues))
+	require.NoError(t, template.Must(template.New("").Parse(tpl)).Execute(&rendered, map[string]interface{}{"Values": chartValues}))
+	var renderedManifest, manifest map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(rendered.String()), &renderedManifest))
+	require.NoError(t, yaml.Unmarshal([]byte(files[groupFailure]), &manifest))
+	require.Equal(t, manifest, renderedManifest)
+	// helm chart has experiment type dirs in templates, run and apply must not read it as a namespace
+	require.NoError(t, writeFiles(m.cfg.Havoc.Dir, out))
+	_, err = m.ReadAllExperiments(m.cfg.Havoc.Dir)
+	require.ErrorContains(t, err, ErrUnreadableOutputFormat)
+
+	out, err = formatFiles(OutputFormatJSON, files)
+	require.NoError(t, err)
+	var index []*ManifestIndexEntry
+	require.NoError(t, json.Unmarshal([]byte(out[JSONIndexFileName]), &index))
+	require.Len(t, index, len(files))
+	entry, ok := lo.Find(index, func(e *ManifestIndexEntry) bool { return e.Path == groupFailure })
+	require.True(t, ok)
+	require.Equal(t, &ManifestIndexEntry{
+		Path:      groupFailure,
+		Type:      ChaosTypeGroupFailure,
+		Kind:      "PodChaos",
+		Name:      "group-failure-havoc-component-group-blockchain-1-fixed",
+		Namespace: Namespace,
+	}, entry)
+}
+
+func TestSmokeTemplateOverrides(t *testing.T) {
+	tplDir := t.TempDir()
+	tpl := `
+apiVersion: chaos-mesh.org/v1alpha1
+kind: PodChaos
+metadata:
+  name: {{ .ExperimentName }}
+  namespace: {{ .Namespace }}
+  labels:
+    team: core
+spec:
+  action: pod-failure
+  mode: {{ .Mode }}
+  duration: {{ .Duration }}
+  selector:
+    fieldSelectors:
+      metadata.name: {{ .PodName }}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tplDir, "PodFailureExperiment.tmpl"), []byte(tpl), os.ModePerm))
+	m, plr := setup(t, "deployment_single_pod.json", "", "templates")
+	m.cfg.Havoc.TemplatesDir = tplDir
+	m, err := NewController(m.cfg)
+	require.NoError(t, err)
+	csp, _, err := m.buildSpecs(Namespace, plr)
+	require.NoError(t, err)
+	require.NotEmpty(t, csp.ExperimentsByType[ChaosTypeFailure])
+	for _, exp := range csp.ExperimentsByType[ChaosTypeFailure] {
+		require.Contains(t, exp, "    team: core\n")
+	}
+	for _, exp := range csp.ExperimentsByType[ChaosTypeLatency] {
+		require.NotContains(t, exp, "team: core")
+	}
+	// templates belong to the controller they are loaded by
+	defaults, _ := setup(t, "deployment_single_pod.json", "", "templates")
+	csp, _, err = defaults.buildSpecs(Namespace, plr)
+	require.NoError(t, err)
+	for _, exp := range csp.ExperimentsByType[ChaosTypeFailure] {
+		require.NotContains(t, exp, "team: core")
+	}
+
+	invalid := strings.Replace(tpl, "kind: PodChaos", "kind: Pod", 1)
+	require.NoError(t, os.WriteFile(filepath.Join(tplDir, "PodFailureExperiment.tmpl"), []byte(invalid), os.ModePerm))
+	m, err = NewController(m.cfg)
+	require.NoError(t, err)
+	_, _, err = m.buildSpecs(Namespace, plr)
+	require.ErrorContains(t, err, ErrInvalidRenderedExperiment)
+
+	require.NoError(t, os.WriteFile(filepath.Join(tplDir, "PodChaos.tmpl"), []byte(tpl), os.ModePerm))
+	_, err = NewController(m.cfg)
+	require.ErrorContains(t, err, ErrUnknownTemplate)
+}
+
+func TestSmokeValidateExperiments(t *testing.T) {
+	valid := PodFailureExperiment{
+		ExperimentName: "failure-pod",
+		Mode:           "one",
+		Namespace:      Namespace,
+		Duration:       "10s",
+		PodName:        "pod",
+	}
+	exp, err := valid.String()
+	require.NoError(t, err)
+	require.NoError(t, ValidateExperiment(exp))
+
+	type test struct {
+		name string
+		exp  func(e PodFailureExperiment) PodFailureExperiment
+		err  string
+	}
+	tests := []test{
+		{
+			name: "malformed duration",
+			exp: func(e PodFailureExperiment) PodFailureExperiment {
+				e.Duration = "10 seconds"
+				return e
+			},
+			err: "duration",
+		},
+		{
+			name: "invalid mode",
+			exp: func(e PodFailureExperiment) PodFailureExperiment {
+				e.Mode = "some"
+				return e
+			},
+			err: "mode",
+		},
+		{
+			name: "invalid mode value",
+			exp: func(e PodFailureExperiment) PodFailureExperiment {
+				e.Mode = "fixed-percent"
+				e.ModeValue = "150"
+				return e
+			},
+			err: "value",
+		},
+		{
+			name: "bad selector",
+			exp: func(e PodFailureExperiment) PodFailureExperiment {
+				e.ExpressionSelectors = "[{key: 'app', operator: Near, values: ['a']}]"
+				return e
+			},
+			err: "operator",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// experiments are validated when they are built, manifests are validated by decoding
+			exp, err := tc.exp(valid).String()
+			if err == nil {
+				err = ValidateExperiment(exp)
+			}
+			require.ErrorContains(t, err, ErrInvalidExperiment)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+
+	unknownField := strings.Replace(exp, "  duration: 10s", "  duration: 10s\n  durations: 10s", 1)
+	require.ErrorContains(t, ValidateExperiment(unknownField), "durations")
+
+	csp := &ChaosSpecs{ExperimentsByType: map[string]map[string]string{
+		ChaosTypeFailure: {"pod": strings.Replace(exp, "mode: one", "mode: some", 1)},
+	}}
+	require.ErrorContains(t, csp.Validate(), "failure/pod")
+}
+
+func TestSmokeTypedObjects(t *testing.T) {
+	exprs := flowExpressionSelectors([]*LabelExpression{{Key: "tier", Operator: LabelOperatorIn, Values: []string{"api"}}})
+	type templateData interface {
+		String() (string, error)
+		Object() (client.Object, error)
+	}
+	tests := []templateData{
+		PodFailureExperiment{ExperimentName: "failure", Mode: "fixed-percent", ModeValue: "50", Namespace: Namespace, Duration: "1m", Selector: "'app': 'node'", ExpressionSelectors: exprs},
+		PodFailureExperiment{ExperimentName: "failure-pod", Mode: "one", Namespace: Namespace, Duration: "10s", PodName: "node-0"},
+		NetworkChaosExperiment{ExperimentName: "latency", Mode: "fixed", ModeValue: "1", Namespace: Namespace, Duration: "10s", Latency: "300ms", Selector: "{'app': 'node', 'tier': 'api'}"},
+		PodStressCPUExperiment{ExperimentName: "cpu", Mode: "all", Namespace: Namespace, Workers: 1, Load: 100, Duration: "10s", PodName: "node-0", ContainerNames: []string{"node"}},
+		PodStressMemoryExperiment{ExperimentName: "memory", Mode: "one", Namespace: Namespace, Workers: 1, Memory: "512MB", Duration: "10s", Selector: "'app': 'node'"},
+		NetworkChaosGroupPartitionExperiment{ExperimentName: "group-partition", ModeFrom: "all", ModeTo: "fixed-percent", ModeToValue: "50", Direction: "both", Namespace: Namespace, TargetNamespace: "other", Duration: "30s", SelectorFrom: "'app': 'node'", SelectorTo: "'app': 'geth'", TargetExpressionSelectors: exprs},
+		NetworkChaosExternalPartitionExperiment{ExperimentName: "external", Namespace: Namespace, Duration: "10s", ExternalURL: "www.google.com"},
+		ContainerKillExperiment{ExperimentName: "container-kill", Mode: "one", Namespace: Namespace, PodName: "node-0", ContainerNames: []string{"node"}},
+		TopologyFailureExperiment{ExperimentName: "zone-failure", Namespace: Namespace, Duration: "1m", NodeSelector: "{'topology.kubernetes.io/zone': 'us-east-1a'}"},
+		TopologyPartitionExperiment{ExperimentName: "node-partition", Namespace: Namespace, Duration: "1m", Direction: "both", Nodes: []string{"node-a"}, TargetNodes: []string{"node-b", "node-c"}},
+		HTTPExperiment{ExperimentName: "http", Mode: "all", Namespace: Namespace, Duration: "10s", Selector: "'app': 'api'", Port: 8080, Target: "Request", Path: "/pets", Method: "GET", Abort: true},
+	}
+	// default templates shipped in templates dir are a starting point for user templates, they render the same objects
+	defaults, err := LoadTemplates("templates")
+	require.NoError(t, err)
+	for _, tc := range tests {
+		manifest, err := tc.String()
+		require.NoError(t, err)
+		decoded, err := decodeExperiment(manifest)
+		require.NoError(t, err)
+		built, err := tc.Object()
+		require.NoError(t, err)
+		require.Equal(t, decoded, built)
+		// defaulted PodChaos grace period isn't serialized
+		require.NotContains(t, manifest, "gracePeriod")
+
+		typeName := reflect.TypeOf(tc).Name()
+		require.Contains(t, defaults, typeName)
+		rendered, err := MarshalTemplate(tc, typeName, defaults[typeName])
+		require.NoError(t, err)
+		fromTemplate, err := decodeExperiment(rendered)
+		require.NoError(t, err, typeName)
+		require.Equal(t, built, fromTemplate, typeName)
+	}
+
+	m, plr := setup(t, "deployment_single_group.json", "", "objects")
+	csp, _, err := m.buildSpecs(Namespace, plr)
+	require.NoError(t, err)
+	for expType, experiments := range csp.ExperimentsByType {
+		require.Len(t, csp.ObjectsByType[expType], len(experiments))
+	}
+	for _, obj := range csp.ObjectsByType[ChaosTypeGroupFailure] {
+		pc, ok := obj.(*v1alpha1.PodChaos)
+		require.True(t, ok)
+		require.Equal(t, v1alpha1.PodFailureAction, pc.Spec.Action)
+		require.Equal(t, Namespace, pc.Namespace)
+	}
+}
@@ -0,0 +1,339 @@
+package k8schaos
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chaos-mesh/chaos-mesh/api/v1alpha1"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// HTTPChaosOpts are options of an HTTPChaos aborting or delaying requests to a port
+type HTTPChaosOpts struct {
+	Name        string
+	Namespace   string
+	Description string
+	DelayCreate time.Duration
+	NodeCount   int
+	Mode        v1alpha1.SelectorMode
+	ModeValue   string
+	Duration    time.Duration
+	Selector    v1alpha1.PodSelectorSpec
+	Target      v1alpha1.PodHttpChaosTarget
+	Port        int32
+	Path        string
+	Method      string
+	Abort       bool
+	Delay       *string
+	K8sClient   client.Client
+	Listeners   []ChaosListener
+	Logger      *zerolog.Logger
+}
+
+// IOChaosOpts are options of an IOChaos delaying or failing file system calls on a volume
+type IOChaosOpts struct {
+	Name           string
+	Namespace      string
+	Description    string
+	DelayCreate    time.Duration
+	NodeCount      int
+	Mode           v1alpha1.SelectorMode
+	ModeValue      string
+	Duration       time.Duration
+	Selector       v1alpha1.PodSelectorSpec
+	ContainerNames []string
+	Action         v1alpha1.IOChaosType
+	VolumePath     string
+	// Path is a glob of affected files, all files on the volume are affected if it's empty
+	Path string
+	// Delay is a latency of every call for "latency" action, ex.: 100ms
+	Delay string
+	// Errno is an error number returned for "fault" action, ex.: 5 for EIO
+	Errno     uint32
+	Percent   int
+	Methods   []v1alpha1.IoMethod
+	K8sClient client.Client
+	Listeners []ChaosListener
+	Logger    *zerolog.Logger
+}
+
+func (o *HTTPChaosOpts) Validate() error {
+	if o.Target != v1alpha1.PodHttpRequest && o.Target != v1alpha1.PodHttpResponse {
+		return fmt.Errorf("target should be Request or Response")
+	}
+	if o.Port <= 0 {
+		return fmt.Errorf("port should be specified")
+	}
+	if !o.Abort && o.Delay == nil {
+		return fmt.Errorf("either abort or delay should be specified")
+	}
+	return nil
+}
+
+func (o *IOChaosOpts) Validate() error {
+	if o.VolumePath == "" {
+		return fmt.Errorf("volume path should be specified")
+	}
+	switch o.Action {
+	case v1alpha1.IoLatency:
+		if _, err := time.ParseDuration(o.Delay); err != nil {
+			return fmt.Errorf("invalid delay: %v", err)
+		}
+	case v1alpha1.IoFaults:
+		if o.Errno == 0 {
+			return fmt.Errorf("errno should be specified")
+		}
+	default:
+		return fmt.Errorf("action should be latency or fault")
+	}
+	if o.Percent < 0 || o.Percent > 100 {
+		return fmt.Errorf("percent should be between 0 and 100")
+	}
+	return nil
+}
+
+func (o *PodChaosOpts) Validate() error {
+	if o.Spec.Action == "" {
+		return fmt.Errorf("action should be specified")
+	}
+	return nil
+}
+
+func (o *StressChaosOpts) Validate() error {
+	if o.Stressors == nil || (o.Stressors.CPUStressor == nil && o.Stressors.MemoryStressor == nil) {
+		return fmt.Errorf("either CPU or memory stressor should be specified")
+	}
+	return nil
+}
+
+// selectorMode returns explicit mode if it's set, otherwise "fixed" mode for NodeCount pods, or "all" pods if NodeCount is 0
+func selectorMode(mode v1alpha1.SelectorMode, value string, nodeCount int) (v1alpha1.SelectorMode, string) {
+	if mode != "" {
+		return mode, value
+	}
+	if nodeCount <= 0 {
+		return v1alpha1.AllMode, ""
+	}
+	return v1alpha1.FixedMode, strconv.Itoa(nodeCount)
+}
+
+// FormatDuration formats duration the way Chaos Mesh manifests usually do, without zero minutes and seconds, ex.: 1m instead of 1m0s
+func FormatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// chaosDuration returns nil for zero duration, chaos runs until it's deleted then
+func chaosDuration(d time.Duration) *string {
+	if d == 0 {
+		return nil
+	}
+	return Ptr(FormatDuration(d))
+}
+
+func objectMeta(name string, namespace string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{Name: name, Namespace: namespace}
+}
+
+func typeMeta(kind string) metav1.TypeMeta {
+	return metav1.TypeMeta{Kind: kind, APIVersion: v1alpha1.GroupVersion.String()}
+}
+
+// BuildNetworkChaos validates options and builds a NetworkChaos object
+func BuildNetworkChaos(opts NetworkChaosOpts) (*v1alpha1.NetworkChaos, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid network chaos options")
+	}
+	var action v1alpha1.NetworkChaosAction
+	switch {
+	case opts.Partition:
+		action = v1alpha1.PartitionAction
+	case opts.Delay != nil:
+		action = v1alpha1.DelayAction
+	default:
+		action = v1alpha1.LossAction
+	}
+	mode, value := selectorMode(opts.Mode, opts.ModeValue, opts.NodeCount)
+	return &v1alpha1.NetworkChaos{
+		TypeMeta:   typeMeta(v1alpha1.KindNetworkChaos),
+		ObjectMeta: objectMeta(opts.Name, opts.Namespace),
+		Spec: v1alpha1.NetworkChaosSpec{
+			Action:   action,
+			Duration: chaosDuration(opts.Duration),
+			PodSelector: v1alpha1.PodSelector{
+				Mode:     mode,
+				Value:    value,
+				Selector: opts.Selector,
+			},
+			Direction:       opts.Direction,
+			Target:          opts.Target,
+			ExternalTargets: opts.ExternalTargets,
+			TcParameter: v1alpha1.TcParameter{
+				Delay: opts.Delay,
+				Loss:  opts.Loss,
+			},
+		},
+	}, nil
+}
+
+// BuildPodChaos validates options and builds a PodChaos object, mode is derived from NodeCount if spec has no mode
+func BuildPodChaos(opts PodChaosOpts) (*v1alpha1.PodChaos, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid pod chaos options")
+	}
+	spec := *opts.Spec.DeepCopy()
+	spec.Mode, spec.Value = selectorMode(spec.Mode, spec.Value, opts.NodeCount)
+	if spec.Duration == nil {
+		spec.Duration = chaosDuration(opts.Duration)
+	}
+	return &v1alpha1.PodChaos{
+		TypeMeta:   typeMeta(v1alpha1.KindPodChaos),
+		ObjectMeta: objectMeta(opts.Name, opts.Namespace),
+		Spec:       spec,
+	}, nil
+}
+
+// BuildStressChaos validates options and builds a StressChaos object
+func BuildStressChaos(opts StressChaosOpts) (*v1alpha1.StressChaos, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid stress chaos options")
+	}
+	mode, value := selectorMode(opts.Mode, opts.ModeValue, opts.NodeCount)
+	return &v1alpha1.StressChaos{
+		TypeMeta:   typeMeta(v1alpha1.KindStressChaos),
+		ObjectMeta: objectMeta(opts.Name, opts.Namespace),
+		Spec: v1alpha1.StressChaosSpec{
+			ContainerSelector: v1alpha1.ContainerSelector{
+				PodSelector: v1alpha1.PodSelector{
+					Mode:     mode,
+					Value:    value,
+					Selector: opts.Selector,
+				},
+				ContainerNames: opts.ContainerNames,
+			},
+			Stressors: opts.Stressors,
+			Duration:  chaosDuration(opts.Duration),
+		},
+	}, nil
+}
+
+// BuildHTTPChaos validates options and builds an HTTPChaos object
+func BuildHTTPChaos(opts HTTPChaosOpts) (*v1alpha1.HTTPChaos, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid HTTP chaos options")
+	}
+	mode, value := selectorMode(opts.Mode, opts.ModeValue, opts.NodeCount)
+	spec := v1alpha1.HTTPChaosSpec{
+		PodSelector: v1alpha1.PodSelector{
+			Mode:     mode,
+			Value:    value,
+			Selector: opts.Selector,
+		},
+		Target:   opts.Target,
+		Port:     opts.Port,
+		Duration: chaosDuration(opts.Duration),
+		PodHttpChaosActions: v1alpha1.PodHttpChaosActions{
+			Delay: opts.Delay,
+		},
+	}
+	if opts.Abort {
+		spec.Abort = Ptr(true)
+	}
+	if opts.Path != "" {
+		spec.Path = Ptr(opts.Path)
+	}
+	if opts.Method != "" {
+		spec.Method = Ptr(opts.Method)
+	}
+	return &v1alpha1.HTTPChaos{
+		TypeMeta:   typeMeta(v1alpha1.KindHTTPChaos),
+		ObjectMeta: objectMeta(opts.Name, opts.Namespace),
+		Spec:       spec,
+	}, nil
+}
+
+// BuildIOChaos validates options and builds an IOChaos object
+func BuildIOChaos(opts IOChaosOpts) (*v1alpha1.IOChaos, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid IO chaos options")
+	}
+	mode, value := selectorMode(opts.Mode, opts.ModeValue, opts.NodeCount)
+	return &v1alpha1.IOChaos{
+		TypeMeta:   typeMeta(v1alpha1.KindIOChaos),
+		ObjectMeta: objectMeta(opts.Name, opts.Namespace),
+		Spec: v1alpha1.IOChaosSpec{
+			ContainerSelector: v1alpha1.ContainerSelector{
+				PodSelector: v1alpha1.PodSelector{
+					Mode:     mode,
+					Value:    value,
+					Selector: opts.Selector,
+				},
+				ContainerNames: opts.ContainerNames,
+			},
+			Action:     opts.Action,
+			Delay:      opts.Delay,
+			Errno:      opts.Errno,
+			Path:       opts.Path,
+			Methods:    opts.Methods,
+			Percent:    opts.Percent,
+			VolumePath: opts.VolumePath,
+			Duration:   chaosDuration(opts.Duration),
+		},
+	}, nil
+}
+
+// EncodeObject encodes a Chaos Mesh object into a YAML manifest that can be applied, status, empty creation timestamp
+// and default PodChaos grace period are omitted
+func EncodeObject(obj client.Object) ([]byte, error) {
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	if _, ok := v1alpha1.AllKinds()[kind]; !ok {
+		return nil, fmt.Errorf("unsupported chaos kind: %q", kind)
+	}
+	d, err := json.Marshal(obj)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not encode chaos object")
+	}
+	var manifest map[string]interface{}
+	if err := json.Unmarshal(d, &manifest); err != nil {
+		return nil, errors.Wrap(err, "could not encode chaos object")
+	}
+	delete(manifest, "status")
+	if meta, ok := manifest["metadata"].(map[string]interface{}); ok && meta["creationTimestamp"] == nil {
+		delete(meta, "creationTimestamp")
+	}
+	// PodChaos grace period is always serialized, 0 is its default
+	if spec, ok := manifest["spec"].(map[string]interface{}); ok && spec["gracePeriod"] == float64(0) {
+		delete(spec, "gracePeriod")
+	}
+	return yaml.Marshal(manifest)
+}
+
+// DecodeObject strictly decodes a YAML or JSON manifest of any Chaos Mesh kind into its v1alpha1 type
+func DecodeObject(manifest []byte) (client.Object, error) {
+	var meta metav1.TypeMeta
+	if err := yaml.Unmarshal(manifest, &meta); err != nil {
+		return nil, errors.Wrap(err, "could not decode chaos kind")
+	}
+	kind, ok := v1alpha1.AllKinds()[meta.Kind]
+	if !ok {
+		return nil, fmt.Errorf("unsupported chaos kind: %q", meta.Kind)
+	}
+	obj := kind.SpawnObject()
+	if err := yaml.UnmarshalStrict(manifest, obj); err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("could not decode %s", meta.Kind))
+	}
+	return obj, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/chaos-mesh/chaos-mesh/api/v1alpha1"
@@ -12,20 +13,27 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// Chaos is a Chaos Mesh experiment, its object and status are updated by the monitor goroutine after the chaos is created,
+// read them with GetObject and GetStatus. Listeners receive a snapshot of the chaos taken when the event happened
 type Chaos struct {
-	Object        client.Object
+	object        client.Object
 	Description   string
 	DelayCreate   time.Duration // Delay before creating the chaos object
-	Status        ChaosStatus
+	status        ChaosStatus
 	Client        client.Client
 	listeners     []ChaosListener
 	cancelMonitor context.CancelFunc
 	startTime     time.Time
 	endTime       time.Time
 	logger        *zerolog.Logger
+	// mu guards object, status, listeners, cancelMonitor and times, it's a pointer so listeners can receive chaos by value
+	mu          *sync.RWMutex
+	dispatcher  *listenerDispatcher
+	creationErr error
 }
 
 // ChaosStatus represents the status of a chaos experiment.
@@ -42,6 +50,9 @@ const (
 	StatusUnknown        ChaosStatus = "unknown" // For any state that doesn't match the above
 )
 
+// finishedDeleteTimeout limits deletion of finished chaos by the monitor, so a hanging API server can't block it forever
+const finishedDeleteTimeout = 30 * time.Second
+
 type ChaosOpts struct {
 	Object      client.Object
 	Description string
@@ -63,12 +74,14 @@ func NewChaos(opts ChaosOpts) (*Chaos, error) {
 	}
 
 	return &Chaos{
-		Object:      opts.Object,
+		object:      opts.Object,
 		Description: opts.Description,
 		DelayCreate: opts.DelayCreate,
 		Client:      opts.Client,
 		listeners:   opts.Listeners,
 		logger:      opts.Logger,
+		mu:          &sync.RWMutex{},
+		dispatcher:  newListenerDispatcher(opts.Logger),
 	}, nil
 }
 
@@ -90,7 +103,7 @@ func (c *Chaos) Create(ctx context.Context) {
 			close(done) // Signal that the operation was canceled
 		case <-timer.C:
 			// Timer expired, check if deletion was not requested
-			if c.Status != StatusDeleted {
+			if c.GetStatus() != StatusDeleted {
 				c.createNow(ctx)
 			}
 			close(done) // Signal that the creation process is either done or skipped
@@ -98,168 +111,195 @@ func (c *Chaos) Create(ctx context.Context) {
 	}()
 }
 
+// Update writes local changes of the chaos object to Kubernetes
 func (c *Chaos) Update(ctx context.Context) error {
-	// Modify the resource
-	// For example, adding or updating an annotation
-	annotations := c.Object.GetAnnotations()
-	if annotations == nil {
-		annotations = make(map[string]string)
-	}
-	annotations["example.com/trigger-injection"] = "true"
-	c.Object.SetAnnotations(annotations)
-
-	if err := c.Client.Update(ctx, c.Object); err != nil {
+	obj := c.GetObject().DeepCopyObject().(client.Object)
+	if err := c.Client.Update(ctx, obj); err != nil {
 		return errors.Wrap(err, "failed to update chaos object")
 	}
+	c.setObject(obj)
 
 	return nil
 }
 
 // createNow is a private method that encapsulates the chaos object creation logic.
 func (c *Chaos) createNow(ctx context.Context) {
-	if err := c.Client.Create(ctx, c.Object); err != nil {
+	obj := c.GetObject().DeepCopyObject().(client.Object)
+	if err := c.Client.Create(ctx, obj); err != nil {
+		c.mu.Lock()
+		c.status = StatusCreationFailed
+		c.creationErr = err
+		c.mu.Unlock()
 		c.notifyListeners(string(StatusCreationFailed), err)
 		return
 	}
-	c.notifyListeners(string(StatusCreated), nil)
 
 	// Create a cancellable context for monitorStatus
 	monitorCtx, cancel := context.WithCancel(ctx)
+	c.mu.Lock()
+	c.object = obj
+	c.status = StatusCreated
 	c.cancelMonitor = cancel
+	c.mu.Unlock()
+	c.notifyListeners(string(StatusCreated), nil)
 	go c.monitorStatus(monitorCtx)
 }
 
+// Pause sets Chaos Mesh pause annotation, injected faults are recovered until the chaos is resumed.
+// Listeners are notified when Chaos Mesh reports the chaos is paused
 func (c *Chaos) Pause(ctx context.Context) error {
-	err := c.updateChaosObject(ctx)
-	if err != nil {
-		return errors.Wrap(err, "could not update the chaos object")
-	}
-
-	annotations := c.Object.GetAnnotations()
-	if annotations == nil {
-		annotations = map[string]string{}
-	}
-	annotations[v1alpha1.PauseAnnotationKey] = strconv.FormatBool(true)
-	c.Object.SetAnnotations(annotations)
-
-	err = c.Client.Update(context.Background(), c.Object)
-	if err != nil {
+	if err := c.setPaused(ctx, true); err != nil {
 		return errors.Wrap(err, "could not update the annotation to set the chaos experiment into pause state")
 	}
-
-	c.notifyListeners("paused", nil)
 	return nil
 }
 
+// Resume removes Chaos Mesh pause annotation, listeners are notified when faults are injected again
 func (c *Chaos) Resume(ctx context.Context) error {
-	// Implement resume logic here
-	c.notifyListeners("resumed", nil)
+	if err := c.setPaused(ctx, false); err != nil {
+		return errors.Wrap(err, "could not remove the annotation to resume the chaos experiment")
+	}
 	return nil
 }
 
+// setPaused toggles pause annotation on the latest version of the chaos object, retrying if it was modified concurrently
+func (c *Chaos) setPaused(ctx context.Context, paused bool) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := c.updateChaosObject(ctx); err != nil {
+			return errors.Wrap(err, "could not update the chaos object")
+		}
+		obj := c.GetObject().DeepCopyObject().(client.Object)
+		annotations := obj.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		if paused {
+			annotations[v1alpha1.PauseAnnotationKey] = strconv.FormatBool(true)
+		} else {
+			delete(annotations, v1alpha1.PauseAnnotationKey)
+		}
+		obj.SetAnnotations(annotations)
+		if err := c.Client.Update(ctx, obj); err != nil {
+			return err
+		}
+		c.setObject(obj)
+		return nil
+	})
+}
+
 func (c *Chaos) Delete(ctx context.Context) error {
 	// Cancel the monitoring goroutine
-	if c.cancelMonitor != nil {
-		c.cancelMonitor()
+	c.mu.RLock()
+	cancelMonitor := c.cancelMonitor
+	status := c.status
+	c.mu.RUnlock()
+	if cancelMonitor != nil {
+		cancelMonitor()
 	}
 
-	// If the chaos was running or paused, update the status and notify listeners
-	if c.Status == StatusPaused || c.Status == StatusRunning {
+	// If the chaos was created, update the status and notify listeners it ended
+	if status == StatusCreated || status == StatusPaused || status == StatusRunning {
 		err := c.updateChaosObject(ctx)
 		if err != nil {
 			return errors.Wrap(err, "could not update the chaos object")
 		}
-		c.Status = StatusFinished
-		c.endTime = time.Now()
-		c.notifyListeners("finished", nil)
+		// the monitor could finish the chaos concurrently, listeners are notified once
+		c.mu.Lock()
+		finished := c.status == StatusCreated || c.status == StatusPaused || c.status == StatusRunning
+		if finished {
+			c.status = StatusFinished
+			c.endTime = time.Now()
+		}
+		c.mu.Unlock()
+		if finished {
+			c.notifyListeners("finished", nil)
+		}
 	}
 
-	if err := c.Client.Delete(ctx, c.Object); err != nil {
+	if err := c.Client.Delete(ctx, c.GetObject().DeepCopyObject().(client.Object)); err != nil {
 		return errors.Wrap(err, "failed to delete chaos object")
 	}
 
-	c.Status = StatusDeleted
+	c.mu.Lock()
+	c.status = StatusDeleted
+	c.mu.Unlock()
 
 	c.logger.Info().Str("name", c.GetChaosName()).Msg("Chaos deleted")
 
 	return nil
 }
 
+// GetObject returns the latest version of the chaos object, it must not be modified
 func (c *Chaos) GetObject() client.Object {
-	return c.Object
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.object
+}
+
+func (c *Chaos) setObject(obj client.Object) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.object = obj
+}
+
+// GetStatus returns the current status of the chaos
+func (c *Chaos) GetStatus() ChaosStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.status
+}
+
+func (c *Chaos) creationError() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.creationErr
 }
 
 func (c *Chaos) GetChaosName() string {
-	return c.Object.GetName()
+	return c.GetObject().GetName()
 }
 
 func (c *Chaos) GetChaosDescription() string {
 	return c.Description
 }
 
+// GetChaosTypeStr returns kind of the chaos object, or "Unknown" if it's not a Chaos Mesh kind
 func (c *Chaos) GetChaosTypeStr() string {
-	switch c.Object.(type) {
-	case *v1alpha1.NetworkChaos:
-		return "NetworkChaos"
-	case *v1alpha1.IOChaos:
-		return "IOChaos"
-	case *v1alpha1.StressChaos:
-		return "StressChaos"
-	case *v1alpha1.PodChaos:
-		return "PodChaos"
-	case *v1alpha1.HTTPChaos:
-		return "HTTPChaos"
-	default:
+	kind, err := c.GetChaosKind()
+	if err != nil {
 		return "Unknown"
 	}
+	return kind
 }
 
+// GetChaosSpec returns spec of the chaos object, or nil if it's not a Chaos Mesh kind
 func (c *Chaos) GetChaosSpec() interface{} {
-	switch spec := c.Object.(type) {
-	case *v1alpha1.NetworkChaos:
-		return spec.Spec
-	case *v1alpha1.IOChaos:
-		return spec.Spec
-	case *v1alpha1.StressChaos:
-		return spec.Spec
-	case *v1alpha1.PodChaos:
-		return spec.Spec
-	case *v1alpha1.HTTPChaos:
-		return spec.Spec
-	default:
+	spec, err := specValue(c.GetObject())
+	if err != nil {
 		return nil
 	}
+	return spec.Interface()
 }
 
 func (c *Chaos) GetChaosDuration() (time.Duration, error) {
-	var durationStr *string
-	switch spec := c.Object.(type) {
-	case *v1alpha1.NetworkChaos:
-		durationStr = spec.Spec.Duration
-	case *v1alpha1.IOChaos:
-		durationStr = spec.Spec.Duration
-	case *v1alpha1.StressChaos:
-		durationStr = spec.Spec.Duration
-	case *v1alpha1.PodChaos:
-		durationStr = spec.Spec.Duration
-	case *v1alpha1.HTTPChaos:
-		durationStr = spec.Spec.Duration
-	}
-
-	if durationStr == nil {
-		return time.Duration(0), fmt.Errorf("could not get duration for chaos object: %v", c.Object)
-	}
-	duration, err := time.ParseDuration(*durationStr)
+	duration, err := specDuration(c.GetObject())
 	if err != nil {
 		return time.Duration(0), fmt.Errorf("could not parse duration: %w", err)
 	}
-	return duration, nil
+	if duration == nil {
+		return time.Duration(0), fmt.Errorf("could not get duration for chaos object: %v", c.GetObject())
+	}
+	return *duration, nil
 }
 
 func (c *Chaos) GetChaosEvents() (*corev1.EventList, error) {
+	kind, err := c.GetChaosKind()
+	if err != nil {
+		return nil, err
+	}
 	listOpts := []client.ListOption{
-		client.InNamespace(c.Object.GetNamespace()),
-		client.MatchingFields{"involvedObject.name": c.Object.GetName(), "involvedObject.kind": c.GetChaosKind()},
+		client.InNamespace(c.GetObject().GetNamespace()),
+		client.MatchingFields{"involvedObject.name": c.GetChaosName(), "involvedObject.kind": kind},
 	}
 	events := &corev1.EventList{}
 	if err := c.Client.List(context.Background(), events, listOpts...); err != nil {
@@ -269,123 +309,60 @@ func (c *Chaos) GetChaosEvents() (*corev1.EventList, error) {
 	return events, nil
 }
 
-func (c *Chaos) GetChaosKind() string {
-	switch c.Object.(type) {
-	case *v1alpha1.NetworkChaos:
-		return "NetworkChaos"
-	case *v1alpha1.IOChaos:
-		return "IOChaos"
-	case *v1alpha1.StressChaos:
-		return "StressChaos"
-	case *v1alpha1.PodChaos:
-		return "PodChaos"
-	case *v1alpha1.HTTPChaos:
-		return "HTTPChaos"
-	default:
-		panic(fmt.Sprintf("could not get chaos kind for object: %v", c.Object))
+// GetChaosKind returns kind of the chaos object, any kind registered by Chaos Mesh is supported
+func (c *Chaos) GetChaosKind() (string, error) {
+	kind, _, err := lookupKind(c.GetObject())
+	if err != nil {
+		return "", fmt.Errorf("could not get chaos kind: %w", err)
 	}
+	return kind, nil
 }
 
 func (c *Chaos) GetChaosStatus() (*v1alpha1.ChaosStatus, error) {
-	switch obj := c.Object.(type) {
-	case *v1alpha1.NetworkChaos:
-		return obj.GetStatus(), nil
-	case *v1alpha1.IOChaos:
-		return obj.GetStatus(), nil
-	case *v1alpha1.StressChaos:
-		return obj.GetStatus(), nil
-	case *v1alpha1.PodChaos:
-		return obj.GetStatus(), nil
-	case *v1alpha1.HTTPChaos:
-		return obj.GetStatus(), nil
-	default:
-		return nil, fmt.Errorf("could not get chaos status for %s", c.GetChaosKind())
+	obj, ok := c.GetObject().(v1alpha1.StatefulObject)
+	if !ok {
+		return nil, fmt.Errorf("could not get chaos status for %T", c.GetObject())
 	}
+	return obj.GetStatus(), nil
 }
 
 func (c *Chaos) GetExperimentStatus() (v1alpha1.ExperimentStatus, error) {
-	switch obj := c.Object.(type) {
-	case *v1alpha1.NetworkChaos:
-		return obj.Status.Experiment, nil
-	case *v1alpha1.IOChaos:
-		return obj.Status.Experiment, nil
-	case *v1alpha1.StressChaos:
-		return obj.Status.Experiment, nil
-	case *v1alpha1.PodChaos:
-		return obj.Status.Experiment, nil
-	case *v1alpha1.HTTPChaos:
-		return obj.Status.Experiment, nil
-	default:
-		return v1alpha1.ExperimentStatus{}, fmt.Errorf("could not experiment status for object: %v", c.Object)
+	status, err := c.GetChaosStatus()
+	if err != nil {
+		return v1alpha1.ExperimentStatus{}, fmt.Errorf("could not experiment status for object: %w", err)
 	}
+	return status.Experiment, nil
 }
 
 func ChaosObjectExists(object client.Object, c client.Client) (bool, error) {
-	switch obj := object.(type) {
-	case *v1alpha1.NetworkChaos, *v1alpha1.IOChaos, *v1alpha1.StressChaos, *v1alpha1.PodChaos, *v1alpha1.HTTPChaos, *v1alpha1.Schedule:
-		err := c.Get(context.Background(), client.ObjectKeyFromObject(obj), obj)
-		if err != nil {
-			if client.IgnoreNotFound(err) == nil {
-				// If the error is NotFound, the object does not exist.
-				return false, nil
-			}
-			// For any other errors, return the error.
-			return false, err
+	if _, _, err := lookupKind(object); err != nil {
+		return false, err
+	}
+	err := c.Get(context.Background(), client.ObjectKeyFromObject(object), object)
+	if err != nil {
+		if client.IgnoreNotFound(err) == nil {
+			// If the error is NotFound, the object does not exist.
+			return false, nil
 		}
-		// If there's no error, the object exists.
-		return true, nil
-	default:
-		return false, fmt.Errorf("unsupported chaos object type: %T", obj)
+		// For any other errors, return the error.
+		return false, err
 	}
+	// If there's no error, the object exists.
+	return true, nil
 }
 
+// updateChaosObject fetches the latest version of the chaos object into a new object of its kind
 func (c *Chaos) updateChaosObject(ctx context.Context) error {
-	switch obj := c.Object.(type) {
-	case *v1alpha1.NetworkChaos:
-		var objOut = &v1alpha1.NetworkChaos{}
-		err := c.Client.Get(ctx, client.ObjectKeyFromObject(obj), objOut)
-		if err != nil {
-			return errors.Wrap(err, "could not get network chaos object")
-		}
-		c.Object = objOut
-	case *v1alpha1.IOChaos:
-		var objOut = &v1alpha1.IOChaos{}
-		err := c.Client.Get(ctx, client.ObjectKeyFromObject(obj), objOut)
-		if err != nil {
-			return errors.Wrap(err, "could not get IO chaos object")
-		}
-		c.Object = objOut
-	case *v1alpha1.StressChaos:
-		var objOut = &v1alpha1.StressChaos{}
-		err := c.Client.Get(ctx, client.ObjectKeyFromObject(obj), objOut)
-		if err != nil {
-			return errors.Wrap(err, "could not get stress chaos object")
-		}
-		c.Object = objOut
-	case *v1alpha1.PodChaos:
-		var objOut = &v1alpha1.PodChaos{}
-		err := c.Client.Get(ctx, client.ObjectKeyFromObject(obj), objOut)
-		if err != nil {
-			return errors.Wrap(err, "could not get pod chaos object")
-		}
-		c.Object = objOut
-	case *v1alpha1.HTTPChaos:
-		var objOut = &v1alpha1.HTTPChaos{}
-		err := c.Client.Get(ctx, client.ObjectKeyFromObject(obj), objOut)
-		if err != nil {
-			return errors.Wrap(err, "could not get HTTP chaos object")
-		}
-		c.Object = objOut
-	case *v1alpha1.Schedule:
-		var objOut = &v1alpha1.Schedule{}
-		err := c.Client.Get(ctx, client.ObjectKeyFromObject(obj), objOut)
-		if err != nil {
-			return errors.Wrap(err, "could not get schedule object")
-		}
-		c.Object = objOut
-	default:
-		return fmt.Errorf("unsupported chaos object type: %T", obj)
+	obj := c.GetObject()
+	kind, chaosKind, err := lookupKind(obj)
+	if err != nil {
+		return err
 	}
+	objOut := chaosKind.SpawnObject()
+	if err := c.Client.Get(ctx, client.ObjectKeyFromObject(obj), objOut); err != nil {
+		return errors.Wrapf(err, "could not get %s object", kind)
+	}
+	c.setObject(objOut)
 
 	return nil
 }
@@ -404,16 +381,29 @@ func isConditionTrue(status *v1alpha1.ChaosStatus, expectedCondition v1alpha1.Ch
 }
 
 func (c *Chaos) AddListener(listener ChaosListener) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.listeners = append(c.listeners, listener)
 }
 
+// RemoveListener removes the listener, events that are already dispatched are still delivered to it
+func (c *Chaos) RemoveListener(listener ChaosListener) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listeners = removeListener(c.listeners, listener)
+}
+
 // GetStartTime returns the time when the chaos experiment started
 func (c *Chaos) GetStartTime() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.startTime
 }
 
 // GetEndTime returns the time when the chaos experiment ended
 func (c *Chaos) GetEndTime() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.endTime
 }
 
@@ -423,7 +413,7 @@ func (c *Chaos) GetExpectedEndTime() (time.Time, error) {
 	if err != nil {
 		return time.Time{}, err
 	}
-	return c.startTime.Add(duration), nil
+	return c.GetStartTime().Add(duration), nil
 }
 
 type ChaosEventDetails struct {
@@ -432,28 +422,83 @@ type ChaosEventDetails struct {
 	Error error
 }
 
+// snapshot returns a copy of the chaos state for listeners, it doesn't change when the chaos is updated
+func (c *Chaos) snapshot() Chaos {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return Chaos{
+		object:      c.object.DeepCopyObject().(client.Object),
+		Description: c.Description,
+		DelayCreate: c.DelayCreate,
+		status:      c.status,
+		Client:      c.Client,
+		startTime:   c.startTime,
+		endTime:     c.endTime,
+		logger:      c.logger,
+		mu:          &sync.RWMutex{},
+	}
+}
+
+// notifyListeners dispatches the event with a snapshot of the chaos to listeners registered at the moment
 func (c *Chaos) notifyListeners(event string, err error) {
-	for _, listener := range c.listeners {
+	chaos := c.snapshot()
+	c.mu.RLock()
+	listeners := append([]ChaosListener(nil), c.listeners...)
+	c.mu.RUnlock()
+	c.dispatcher.dispatch(event, listeners, func(listener ChaosListener) {
 		switch event {
 		case "created":
-			listener.OnChaosCreated(*c)
+			listener.OnChaosCreated(chaos)
 		case string(StatusCreationFailed):
-			listener.OnChaosCreationFailed(*c, err)
+			listener.OnChaosCreationFailed(chaos, err)
 		case "started":
-			listener.OnChaosStarted(*c)
+			listener.OnChaosStarted(chaos)
 		case "paused":
-			listener.OnChaosPaused(*c)
+			listener.OnChaosPaused(chaos)
 		case "resumed":
-			listener.OnChaosStarted(*c) // Assuming "resumed" triggers "started"
+			listener.OnChaosResumed(chaos)
 		case "finished":
-			listener.OnChaosEnded(*c)
+			listener.OnChaosEnded(chaos)
 		case "unknown":
-			listener.OnChaosStatusUnknown(*c)
+			listener.OnChaosStatusUnknown(chaos)
 		}
-	}
+	})
 }
 
+// monitorStatus tracks status of the chaos object with a watch shared by all objects of its kind,
+// clients without watch support are polled every second
 func (c *Chaos) monitorStatus(ctx context.Context) {
+	wc, ok := c.Client.(client.WithWatch)
+	if !ok {
+		c.pollStatus(ctx)
+		return
+	}
+	updates, unsubscribe, err := subscribeObject(wc, c.GetObject(), c.logger)
+	if err != nil {
+		c.logger.Error().Err(err).Msg("failed to watch chaos object")
+		c.pollStatus(ctx)
+		return
+	}
+	defer unsubscribe()
+
+	// the object could change before the watch was started
+	if err := c.updateChaosObject(ctx); err != nil {
+		c.logger.Error().Err(err).Msg("failed to update chaos object")
+	} else {
+		c.handleStatus()
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case obj := <-updates:
+			c.setObject(obj)
+			c.handleStatus()
+		}
+	}
+}
+
+func (c *Chaos) pollStatus(ctx context.Context) {
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
 
@@ -467,81 +512,132 @@ func (c *Chaos) monitorStatus(ctx context.Context) {
 				c.logger.Error().Err(err).Msg("failed to update chaos object")
 				continue
 			}
-			chaosStatus, err := c.GetChaosStatus()
-			if err != nil {
-				c.logger.Error().Err(err).Msg("failed to get chaos status")
-				continue
-			}
+			c.handleStatus()
+		}
+	}
+}
 
-			var currentStatus ChaosStatus
+// handleStatus derives chaos status from conditions of the latest chaos object and notifies listeners if it changed
+func (c *Chaos) handleStatus() {
+	chaosStatus, err := c.GetChaosStatus()
+	if err != nil {
+		c.logger.Error().Err(err).Msg("failed to get chaos status")
+		return
+	}
+	// Chaos Mesh hasn't reconciled the new object yet
+	if chaosStatus == nil || len(chaosStatus.Conditions) == 0 {
+		return
+	}
 
-			allRecovered := v1alpha1.ChaosCondition{
-				Type:   v1alpha1.ConditionAllRecovered,
-				Status: corev1.ConditionTrue,
-			}
-			allInjected := v1alpha1.ChaosCondition{
-				Type:   v1alpha1.ConditionAllInjected,
-				Status: corev1.ConditionTrue,
-			}
-			selected := v1alpha1.ChaosCondition{
-				Type:   v1alpha1.ConditionSelected,
-				Status: corev1.ConditionTrue,
-			}
-			paused := v1alpha1.ChaosCondition{
-				Type:   v1alpha1.ConditionPaused,
-				Status: corev1.ConditionTrue,
-			}
+	var currentStatus ChaosStatus
 
-			if isConditionTrue(chaosStatus, selected) && isConditionTrue(chaosStatus, allInjected) {
-				currentStatus = StatusRunning
-			} else if isConditionTrue(chaosStatus, allRecovered) {
-				currentStatus = StatusFinished
-			} else if !isConditionTrue(chaosStatus, paused) && !isConditionTrue(chaosStatus, selected) {
-				currentStatus = StatusUnknown
-			}
+	allRecovered := v1alpha1.ChaosCondition{
+		Type:   v1alpha1.ConditionAllRecovered,
+		Status: corev1.ConditionTrue,
+	}
+	allInjected := v1alpha1.ChaosCondition{
+		Type:   v1alpha1.ConditionAllInjected,
+		Status: corev1.ConditionTrue,
+	}
+	selected := v1alpha1.ChaosCondition{
+		Type:   v1alpha1.ConditionSelected,
+		Status: corev1.ConditionTrue,
+	}
+	paused := v1alpha1.ChaosCondition{
+		Type:   v1alpha1.ConditionPaused,
+		Status: corev1.ConditionTrue,
+	}
 
-			// If the status is unknown, always notify listeners
-			if currentStatus == StatusUnknown {
-				c.notifyListeners(string(StatusUnknown), nil)
-				continue
-			}
+	// Paused chaos is recovered, so pause is checked before injection and recovery
+	if isConditionTrue(chaosStatus, paused) {
+		currentStatus = StatusPaused
+	} else if isConditionTrue(chaosStatus, selected) && isConditionTrue(chaosStatus, allInjected) {
+		currentStatus = StatusRunning
+	} else if isConditionTrue(chaosStatus, allRecovered) {
+		currentStatus = StatusFinished
+	} else if !isConditionTrue(chaosStatus, paused) && !isConditionTrue(chaosStatus, selected) {
+		currentStatus = StatusUnknown
+	}
 
-			// If the status has changed, update internal status and notify listeners
-			if c.Status != currentStatus {
-				c.Status = currentStatus
-
-				switch c.Status {
-				case StatusCreated:
-					c.notifyListeners("created", nil)
-				case StatusRunning:
-					c.startTime = time.Now()
-					c.notifyListeners("started", nil)
-				case StatusPaused:
-					c.notifyListeners("paused", nil)
-				case StatusFinished:
-					c.endTime = time.Now()
-					c.notifyListeners("finished", nil)
-					// Delete the chaos object when it finishes
-					err := c.Delete(context.Background())
-					if err != nil {
-						c.logger.Error().Err(err).Msg("failed to delete chaos object")
-					}
-				}
-			}
+	// Conditions are in transition, ex.: pods are selected but faults are not injected yet
+	if currentStatus == "" {
+		return
+	}
+
+	// If the status is unknown, always notify listeners
+	if currentStatus == StatusUnknown {
+		c.notifyListeners(string(StatusUnknown), nil)
+		return
+	}
+
+	// Status is changed atomically, so concurrent Delete or a late watch event can't notify listeners twice
+	c.mu.Lock()
+	previousStatus := c.status
+	switch {
+	case previousStatus == currentStatus, previousStatus == StatusFinished, previousStatus == StatusDeleted:
+		c.mu.Unlock()
+		return
+	case previousStatus == StatusPaused && currentStatus == StatusFinished:
+		// Resumed chaos stays recovered until faults are injected again, it's not finished
+		c.mu.Unlock()
+		return
+	}
+	c.status = currentStatus
+	switch currentStatus {
+	case StatusRunning:
+		if previousStatus != StatusPaused {
+			c.startTime = time.Now()
+		}
+	case StatusFinished:
+		c.endTime = time.Now()
+	}
+	c.mu.Unlock()
+
+	// If the status has changed, notify listeners
+	switch currentStatus {
+	case StatusRunning:
+		if previousStatus == StatusPaused {
+			c.notifyListeners("resumed", nil)
+			break
+		}
+		c.notifyListeners("started", nil)
+	case StatusPaused:
+		c.notifyListeners("paused", nil)
+	case StatusFinished:
+		c.notifyListeners("finished", nil)
+		// Delete the chaos object when it finishes, not with the monitor context, Delete cancels it
+		ctx, cancel := context.WithTimeout(context.Background(), finishedDeleteTimeout)
+		defer cancel()
+		err := c.Delete(ctx)
+		if err != nil {
+			c.logger.Error().Err(err).Msg("failed to delete chaos object")
 		}
 	}
 }
 
 type NetworkChaosOpts struct {
 	Name        string
+	Namespace   string
 	Description string
 	DelayCreate time.Duration
 	Delay       *v1alpha1.DelaySpec
 	Loss        *v1alpha1.LossSpec
 	NodeCount   int
-	Duration    time.Duration
-	Selector    v1alpha1.PodSelectorSpec
-	K8sClient   client.Client
+	// Mode and ModeValue override mode derived from NodeCount, ex.: fixed-percent
+	Mode      v1alpha1.SelectorMode
+	ModeValue string
+	Duration  time.Duration
+	Selector  v1alpha1.PodSelectorSpec
+	Direction v1alpha1.Direction
+	Target    *v1alpha1.PodSelector
+	// Partition blocks traffic between selected pods and target instead of delaying or dropping it
+	Partition bool
+	// ExternalTargets are IPs or domains outside of the cluster, ex.: to partition pods from an external service
+	ExternalTargets []string
+	K8sClient       client.Client
+	// Listeners and Logger are used by NewNetworkChaos, default package Logger is used if it's nil
+	Listeners []ChaosListener
+	Logger    *zerolog.Logger
 }
 
 func (o *NetworkChaosOpts) Validate() error {
@@ -563,8 +659,8 @@ func (o *NetworkChaosOpts) Validate() error {
 			return fmt.Errorf("loss should be less than 100")
 		}
 	}
-	if o.Loss == nil && o.Delay == nil {
-		return fmt.Errorf("either delay or loss should be specified")
+	if o.Loss == nil && o.Delay == nil && !o.Partition {
+		return fmt.Errorf("either delay, loss or partition should be specified")
 	}
 	return nil
 
@@ -572,23 +668,33 @@ func (o *NetworkChaosOpts) Validate() error {
 
 type PodChaosOpts struct {
 	Name        string
+	Namespace   string
 	Description string
 	DelayCreate time.Duration
 	NodeCount   int
 	Duration    time.Duration
 	Spec        v1alpha1.PodChaosSpec
 	K8sClient   client.Client
+	Listeners   []ChaosListener
+	Logger      *zerolog.Logger
 }
 
 type StressChaosOpts struct {
 	Name        string
+	Namespace   string
 	Description string
 	DelayCreate time.Duration
 	NodeCount   int
-	Stressors   *v1alpha1.Stressors
-	Duration    time.Duration
-	Selector    v1alpha1.PodSelectorSpec
-	K8sClient   client.Client
+	// Mode and ModeValue override mode derived from NodeCount, ex.: fixed-percent
+	Mode           v1alpha1.SelectorMode
+	ModeValue      string
+	Stressors      *v1alpha1.Stressors
+	Duration       time.Duration
+	Selector       v1alpha1.PodSelectorSpec
+	ContainerNames []string
+	K8sClient      client.Client
+	Listeners      []ChaosListener
+	Logger         *zerolog.Logger
 }
 
 // NewChaosMeshClient initializes and returns a new Kubernetes client configured for Chaos Mesh
@@ -605,8 +711,8 @@ func NewChaosMeshClient() (client.Client, error) {
 		return nil, errors.Wrap(err, "could not add the Chaos Mesh scheme")
 	}
 
-	// Create a new client for the Chaos Mesh API
-	chaosClient, err := client.New(config, client.Options{Scheme: scheme.Scheme})
+	// Create a new client for the Chaos Mesh API, watches are used to track chaos status
+	chaosClient, err := client.NewWithWatch(config, client.Options{Scheme: scheme.Scheme})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create a client for Chaos Mesh")
 	}
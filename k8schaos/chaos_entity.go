@@ -15,6 +15,14 @@ type ChaosEntity interface {
 	Delete(ctx context.Context) error
 	// Registers a listener to receive updates about the chaos object's lifecycle.
 	AddListener(listener ChaosListener)
+	// Removes a listener registered with AddListener.
+	RemoveListener(listener ChaosListener)
+	// Pause sets Chaos Mesh pause annotation, schedules don't spawn chaos objects while they are paused.
+	Pause(ctx context.Context) error
+	// Resume removes Chaos Mesh pause annotation.
+	Resume(ctx context.Context) error
+	// GetStatus returns the current status, schedules are running since they are created until they are deleted or paused.
+	GetStatus() ChaosStatus
 
 	GetObject() client.Object
 	GetChaosName() string
@@ -1,44 +1,187 @@
 package k8schaos
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"sync"
 	"time"
 )
 
 // WaitForAllChaosRunning waits for all chaos experiments to be running
 func WaitForAllChaosRunning(chaosObjects []*Chaos, timeoutDuration time.Duration) error {
-	timeout := time.NewTimer(timeoutDuration)
-	defer timeout.Stop()
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutDuration)
+	defer cancel()
+	entities := make([]ChaosEntity, 0, len(chaosObjects))
+	for _, c := range chaosObjects {
+		entities = append(entities, c)
+	}
+	return WaitForStatus(ctx, StatusRunning, entities...)
+}
+
+// WaitForStatus waits until all entities have the status, it returns as soon as any entity failed to be created
+// or ended before reaching the status with errors of all such entities, or errors of entities that didn't reach it when ctx is done
+func WaitForStatus(ctx context.Context, status ChaosStatus, entities ...ChaosEntity) error {
+	return waitForEntities(ctx, string(status), func(s ChaosStatus) bool {
+		return s == status
+	}, entities)
+}
+
+// WaitForEnded waits until all entities are finished or deleted
+func WaitForEnded(ctx context.Context, entities ...ChaosEntity) error {
+	return waitForEntities(ctx, "ended", func(s ChaosStatus) bool {
+		return s == StatusFinished || s == StatusDeleted
+	}, entities)
+}
+
+// WaitForRecovered waits until faults of all entities are recovered: chaos is finished, paused or deleted
+func WaitForRecovered(ctx context.Context, entities ...ChaosEntity) error {
+	return waitForEntities(ctx, "recovered", func(s ChaosStatus) bool {
+		return s == StatusFinished || s == StatusPaused || s == StatusDeleted
+	}, entities)
+}
 
-	ticker := time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
+// statusWaiter is a listener tracking status of one entity from its lifecycle events
+type statusWaiter struct {
+	name     string
+	notify   chan struct{}
+	mu       sync.Mutex
+	status   ChaosStatus
+	err      error
+	received bool
+}
 
-	runningStatus := make(map[*Chaos]bool)
-	for _, chaos := range chaosObjects {
-		runningStatus[chaos] = false
+func waitForEntities(ctx context.Context, state string, reached func(s ChaosStatus) bool, entities []ChaosEntity) error {
+	notify := make(chan struct{}, 1)
+	waiters := make([]*statusWaiter, 0, len(entities))
+	for _, e := range entities {
+		w := &statusWaiter{name: chaosEntityName(e), notify: notify}
+		e.AddListener(w)
+		defer e.RemoveListener(w)
+		// events that happened before the listener was added are not delivered to it
+		w.init(e.GetStatus(), entityCreationError(e))
+		waiters = append(waiters, w)
 	}
 
 	for {
-		allRunning := true
-
-		select {
-		case <-timeout.C:
-			return errors.New("timeout reached before all chaos experiments became running")
-		case <-ticker.C:
-			for chaos, isRunning := range runningStatus {
-				if !isRunning { // Only check if not already marked as running
-					if chaos.Status == StatusRunning {
-						runningStatus[chaos] = true
-					} else {
-						allRunning = false
-					}
-				}
+		var errs []error
+		pending := make([]*statusWaiter, 0)
+		for _, w := range waiters {
+			status, err := w.get()
+			switch {
+			case err != nil:
+				errs = append(errs, fmt.Errorf("%s: %w", w.name, err))
+			case status == StatusCreationFailed:
+				errs = append(errs, fmt.Errorf("%s: chaos creation failed", w.name))
+			case reached(status):
+			case status == StatusFinished || status == StatusDeleted:
+				errs = append(errs, fmt.Errorf("%s: chaos is %s, it can't become %s", w.name, status, state))
+			default:
+				pending = append(pending, w)
 			}
-
-			if allRunning {
-				return nil // All chaos objects are running, can exit
+		}
+		// entities that failed will never reach the state, so the rest is not awaited
+		if len(errs) > 0 || len(pending) == 0 {
+			return errors.Join(errs...)
+		}
+		select {
+		case <-ctx.Done():
+			for _, w := range pending {
+				status, _ := w.get()
+				errs = append(errs, fmt.Errorf("%s: chaos is %q, not %s: %w", w.name, status, state, ctx.Err()))
 			}
-			// Otherwise, continue the loop
+			return errors.Join(errs...)
+		case <-notify:
 		}
 	}
 }
+
+// entityCreationError returns the error entity failed to be created with
+func entityCreationError(e ChaosEntity) error {
+	if ce, ok := e.(interface{ creationError() error }); ok {
+		return ce.creationError()
+	}
+	return nil
+}
+
+// init sets the status the entity had when the waiter was added unless an event was received already
+func (w *statusWaiter) init(status ChaosStatus, creationErr error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.received {
+		w.status = status
+		w.err = creationErr
+	}
+}
+
+func (w *statusWaiter) get() (ChaosStatus, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.status, w.err
+}
+
+func (w *statusWaiter) set(status ChaosStatus, err error) {
+	w.mu.Lock()
+	w.received = true
+	w.status = status
+	if err != nil {
+		w.err = err
+	}
+	w.mu.Unlock()
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (w *statusWaiter) OnChaosCreated(chaos Chaos) {
+	w.set(StatusCreated, nil)
+}
+
+func (w *statusWaiter) OnChaosCreationFailed(chaos Chaos, reason error) {
+	w.set(StatusCreationFailed, reason)
+}
+
+func (w *statusWaiter) OnChaosStarted(chaos Chaos) {
+	w.set(StatusRunning, nil)
+}
+
+func (w *statusWaiter) OnChaosPaused(chaos Chaos) {
+	w.set(StatusPaused, nil)
+}
+
+func (w *statusWaiter) OnChaosResumed(chaos Chaos) {
+	w.set(StatusRunning, nil)
+}
+
+func (w *statusWaiter) OnChaosEnded(chaos Chaos) {
+	w.set(StatusFinished, nil)
+}
+
+func (w *statusWaiter) OnChaosStatusUnknown(chaos Chaos) {
+	w.set(StatusUnknown, nil)
+}
+
+func (w *statusWaiter) OnScheduleCreated(s Schedule) {
+	w.set(StatusRunning, nil)
+}
+
+func (w *statusWaiter) OnScheduleCreationFailed(s Schedule, reason error) {
+	w.set(StatusCreationFailed, reason)
+}
+
+func (w *statusWaiter) OnSchedulePaused(s Schedule) {
+	w.set(StatusPaused, nil)
+}
+
+func (w *statusWaiter) OnScheduleResumed(s Schedule) {
+	w.set(StatusRunning, nil)
+}
+
+func (w *statusWaiter) OnScheduledChaosStarted(s Schedule, spawned ScheduledChaos) {}
+
+func (w *statusWaiter) OnScheduledChaosEnded(s Schedule, spawned ScheduledChaos) {}
+
+func (w *statusWaiter) OnScheduleDeleted(s Schedule) {
+	w.set(StatusDeleted, nil)
+}
@@ -0,0 +1,109 @@
+package k8schaos
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/chaos-mesh/chaos-mesh/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newTestSchedule(t *testing.T, c client.Client, name string, listeners ...ChaosListener) *Schedule {
+	s, err := NewSchedule(ScheduleOpts{
+		Object: &v1alpha1.Schedule{
+			ObjectMeta: objectMeta(name, testNamespace),
+			Spec: v1alpha1.ScheduleSpec{
+				Schedule: "@every 1m",
+				Type:     v1alpha1.ScheduleTypePodChaos,
+			},
+		},
+		Duration:  time.Hour,
+		Client:    c,
+		Listeners: listeners,
+		Logger:    &Logger,
+	})
+	require.NoError(t, err)
+	return s
+}
+
+func TestWaitForStatusAndRecovered(t *testing.T) {
+	c := newFakeClient(t)
+	first := newTestPodChaos(t, c, "pod-failure-1")
+	second := newTestPodChaos(t, c, "pod-failure-2")
+	ctx, cancel := context.WithTimeout(context.Background(), testEventTimeout)
+	defer cancel()
+
+	first.Create(ctx)
+	second.Create(ctx)
+	injectWhenCreated(t, c, "pod-failure-1")
+	injectWhenCreated(t, c, "pod-failure-2")
+	require.NoError(t, WaitForStatus(ctx, StatusRunning, first, second))
+	// the status reached before waiting is not awaited again
+	require.NoError(t, WaitForAllChaosRunning([]*Chaos{first, second}, testEventTimeout))
+
+	require.NoError(t, first.Pause(ctx))
+	setConditions(t, c, "pod-failure-1", map[v1alpha1.ChaosConditionType]corev1.ConditionStatus{
+		v1alpha1.ConditionSelected:     corev1.ConditionTrue,
+		v1alpha1.ConditionAllRecovered: corev1.ConditionTrue,
+		v1alpha1.ConditionPaused:       corev1.ConditionTrue,
+	})
+	setConditions(t, c, "pod-failure-2", map[v1alpha1.ChaosConditionType]corev1.ConditionStatus{
+		v1alpha1.ConditionSelected:     corev1.ConditionTrue,
+		v1alpha1.ConditionAllRecovered: corev1.ConditionTrue,
+	})
+	require.NoError(t, WaitForRecovered(ctx, first, second))
+	require.NoError(t, WaitForEnded(ctx, second))
+
+	err := WaitForStatus(ctx, StatusRunning, second)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "can't become running")
+	require.NoError(t, first.Delete(ctx))
+}
+
+func TestWaitForStatusReturnsCreationFailures(t *testing.T) {
+	c := newFakeClient(t)
+	require.NoError(t, c.Create(context.Background(), &v1alpha1.PodChaos{ObjectMeta: objectMeta("pod-failure-2", testNamespace)}))
+	first := newTestPodChaos(t, c, "pod-failure-1")
+	second := newTestPodChaos(t, c, "pod-failure-2")
+	ctx, cancel := context.WithTimeout(context.Background(), testEventTimeout)
+	defer cancel()
+
+	first.Create(ctx)
+	second.Create(ctx)
+	injectWhenCreated(t, c, "pod-failure-1")
+	err := WaitForStatus(ctx, StatusRunning, first, second)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), testNamespace+"/pod-failure-2")
+	require.Contains(t, err.Error(), "already exists")
+	require.NotContains(t, err.Error(), testNamespace+"/pod-failure-1")
+
+	waitCtx, waitCancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer waitCancel()
+	err = WaitForEnded(waitCtx, first)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Contains(t, err.Error(), `chaos is "running", not ended`)
+	require.NoError(t, first.Delete(ctx))
+	require.NoError(t, WaitForEnded(ctx, first))
+}
+
+func TestWaitForSchedule(t *testing.T) {
+	c := newFakeClient(t)
+	schedule := newTestSchedule(t, c, "pod-failure-schedule")
+	ctx, cancel := context.WithTimeout(context.Background(), testEventTimeout)
+	defer cancel()
+
+	schedule.Create(ctx)
+	require.NoError(t, WaitForStatus(ctx, StatusRunning, schedule))
+	require.NoError(t, schedule.Delete(ctx))
+	require.NoError(t, WaitForEnded(ctx, schedule))
+
+	failed := newTestSchedule(t, c, "pod-failure-schedule-2")
+	require.NoError(t, c.Create(ctx, failed.GetObject().DeepCopyObject().(client.Object)))
+	failed.Create(ctx)
+	err := WaitForStatus(ctx, StatusRunning, failed)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "already exists")
+}
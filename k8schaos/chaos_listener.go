@@ -5,8 +5,14 @@ type ChaosListener interface {
 	OnChaosCreationFailed(chaos Chaos, reason error)
 	OnChaosStarted(chaos Chaos)
 	OnChaosPaused(chaos Chaos)
+	OnChaosResumed(chaos Chaos)       // When the paused chaos is injected again
 	OnChaosEnded(chaos Chaos)         // When the chaos is finished or deleted
 	OnChaosStatusUnknown(chaos Chaos) // When the chaos status is unknown
 	OnScheduleCreated(chaos Schedule)
-	OnScheduleDeleted(chaos Schedule) // When the chaos is finished or deleted
+	OnScheduleCreationFailed(chaos Schedule, reason error)
+	OnSchedulePaused(chaos Schedule)
+	OnScheduleResumed(chaos Schedule)
+	OnScheduledChaosStarted(chaos Schedule, spawned ScheduledChaos) // When the schedule spawns a chaos object
+	OnScheduledChaosEnded(chaos Schedule, spawned ScheduledChaos)   // When the spawned chaos object is no longer active
+	OnScheduleDeleted(chaos Schedule)                               // When the chaos is finished or deleted
 }
@@ -0,0 +1,304 @@
+package k8schaos
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/chaos-mesh/chaos-mesh/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// eventRecorder is a listener recording names of lifecycle events
+type eventRecorder struct {
+	events chan string
+}
+
+func newEventRecorder() *eventRecorder {
+	return &eventRecorder{events: make(chan string, 100)}
+}
+
+func (r *eventRecorder) OnChaosCreated(chaos Chaos) { r.events <- "created" }
+func (r *eventRecorder) OnChaosCreationFailed(chaos Chaos, reason error) {
+	r.events <- "creation_failed"
+}
+func (r *eventRecorder) OnChaosStarted(chaos Chaos)       { r.events <- "started" }
+func (r *eventRecorder) OnChaosPaused(chaos Chaos)        { r.events <- "paused" }
+func (r *eventRecorder) OnChaosResumed(chaos Chaos)       { r.events <- "resumed" }
+func (r *eventRecorder) OnChaosEnded(chaos Chaos)         { r.events <- "ended" }
+func (r *eventRecorder) OnChaosStatusUnknown(chaos Chaos) { r.events <- "unknown" }
+func (r *eventRecorder) OnScheduleCreated(s Schedule)     { r.events <- "schedule_created" }
+func (r *eventRecorder) OnScheduleDeleted(s Schedule)     { r.events <- "schedule_deleted" }
+func (r *eventRecorder) OnScheduleCreationFailed(s Schedule, reason error) {
+	r.events <- "schedule_creation_failed"
+}
+func (r *eventRecorder) OnSchedulePaused(s Schedule)  { r.events <- "schedule_paused" }
+func (r *eventRecorder) OnScheduleResumed(s Schedule) { r.events <- "schedule_resumed" }
+func (r *eventRecorder) OnScheduledChaosStarted(s Schedule, spawned ScheduledChaos) {
+	r.events <- "scheduled_chaos_started"
+}
+func (r *eventRecorder) OnScheduledChaosEnded(s Schedule, spawned ScheduledChaos) {
+	r.events <- "scheduled_chaos_ended"
+}
+
+func (r *eventRecorder) requireEvent(t *testing.T, event string) {
+	t.Helper()
+	select {
+	case e := <-r.events:
+		require.Equal(t, event, e)
+	case <-time.After(testEventTimeout):
+		t.Fatalf("timed out waiting for %s event", event)
+	}
+}
+
+func newTestPodChaos(t *testing.T, c client.Client, name string, listeners ...ChaosListener) *Chaos {
+	chaos, err := NewPodChaos(PodChaosOpts{
+		Name:      name,
+		Namespace: testNamespace,
+		Duration:  time.Minute,
+		Spec:      v1alpha1.PodChaosSpec{Action: v1alpha1.PodFailureAction},
+		K8sClient: c,
+		Listeners: listeners,
+	})
+	require.NoError(t, err)
+	return chaos
+}
+
+// setConditions sets chaos conditions the way Chaos Mesh controller does
+func setConditions(t *testing.T, c client.Client, name string, conditions map[v1alpha1.ChaosConditionType]corev1.ConditionStatus) {
+	t.Helper()
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		obj := &v1alpha1.PodChaos{}
+		if err := c.Get(context.Background(), client.ObjectKey{Namespace: testNamespace, Name: name}, obj); err != nil {
+			return err
+		}
+		obj.Status.Conditions = nil
+		for _, ct := range []v1alpha1.ChaosConditionType{
+			v1alpha1.ConditionSelected,
+			v1alpha1.ConditionAllInjected,
+			v1alpha1.ConditionAllRecovered,
+			v1alpha1.ConditionPaused,
+		} {
+			status, ok := conditions[ct]
+			if !ok {
+				status = corev1.ConditionFalse
+			}
+			obj.Status.Conditions = append(obj.Status.Conditions, v1alpha1.ChaosCondition{Type: ct, Status: status})
+		}
+		return c.Update(context.Background(), obj)
+	})
+	require.NoError(t, err)
+}
+
+func watchersOf(c client.WithWatch) int {
+	watchersMu.Lock()
+	defer watchersMu.Unlock()
+	n := 0
+	for key := range watchers {
+		if key.client == clientKey(c) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestChaosWatchLifecycle(t *testing.T) {
+	c := newFakeClient(t)
+	rec := newEventRecorder()
+	chaos := newTestPodChaos(t, c, "pod-failure", rec)
+
+	chaos.Create(context.Background())
+	rec.requireEvent(t, "created")
+
+	setConditions(t, c, "pod-failure", map[v1alpha1.ChaosConditionType]corev1.ConditionStatus{
+		v1alpha1.ConditionSelected:    corev1.ConditionTrue,
+		v1alpha1.ConditionAllInjected: corev1.ConditionTrue,
+	})
+	rec.requireEvent(t, "started")
+
+	require.NoError(t, chaos.Pause(context.Background()))
+	obj := &v1alpha1.PodChaos{}
+	require.NoError(t, c.Get(context.Background(), client.ObjectKey{Namespace: testNamespace, Name: "pod-failure"}, obj))
+	require.Equal(t, "true", obj.GetAnnotations()[v1alpha1.PauseAnnotationKey])
+	setConditions(t, c, "pod-failure", map[v1alpha1.ChaosConditionType]corev1.ConditionStatus{
+		v1alpha1.ConditionSelected:     corev1.ConditionTrue,
+		v1alpha1.ConditionAllRecovered: corev1.ConditionTrue,
+		v1alpha1.ConditionPaused:       corev1.ConditionTrue,
+	})
+	rec.requireEvent(t, "paused")
+
+	require.NoError(t, chaos.Resume(context.Background()))
+	require.NoError(t, c.Get(context.Background(), client.ObjectKey{Namespace: testNamespace, Name: "pod-failure"}, obj))
+	require.NotContains(t, obj.GetAnnotations(), v1alpha1.PauseAnnotationKey)
+	// faults are not injected right after resume, chaos must not be reported as finished
+	setConditions(t, c, "pod-failure", map[v1alpha1.ChaosConditionType]corev1.ConditionStatus{
+		v1alpha1.ConditionSelected:     corev1.ConditionTrue,
+		v1alpha1.ConditionAllRecovered: corev1.ConditionTrue,
+	})
+	setConditions(t, c, "pod-failure", map[v1alpha1.ChaosConditionType]corev1.ConditionStatus{
+		v1alpha1.ConditionSelected:    corev1.ConditionTrue,
+		v1alpha1.ConditionAllInjected: corev1.ConditionTrue,
+	})
+	rec.requireEvent(t, "resumed")
+
+	setConditions(t, c, "pod-failure", map[v1alpha1.ChaosConditionType]corev1.ConditionStatus{
+		v1alpha1.ConditionSelected:     corev1.ConditionTrue,
+		v1alpha1.ConditionAllRecovered: corev1.ConditionTrue,
+	})
+	rec.requireEvent(t, "ended")
+	require.Eventually(t, func() bool {
+		exists, err := ChaosObjectExists(&v1alpha1.PodChaos{ObjectMeta: objectMeta("pod-failure", testNamespace)}, c)
+		return err == nil && !exists
+	}, testEventTimeout, 10*time.Millisecond)
+}
+
+func TestChaosWatchIsShared(t *testing.T) {
+	c := newFakeClient(t)
+	rec1, rec2 := newEventRecorder(), newEventRecorder()
+	chaos1 := newTestPodChaos(t, c, "pod-failure-1", rec1)
+	chaos2 := newTestPodChaos(t, c, "pod-failure-2", rec2)
+
+	chaos1.Create(context.Background())
+	chaos2.Create(context.Background())
+	rec1.requireEvent(t, "created")
+	rec2.requireEvent(t, "created")
+
+	setConditions(t, c, "pod-failure-2", map[v1alpha1.ChaosConditionType]corev1.ConditionStatus{
+		v1alpha1.ConditionSelected:    corev1.ConditionTrue,
+		v1alpha1.ConditionAllInjected: corev1.ConditionTrue,
+	})
+	rec2.requireEvent(t, "started")
+	require.Equal(t, 1, watchersOf(c))
+	require.Empty(t, rec1.events, "events of other chaos objects must not be dispatched")
+
+	require.NoError(t, chaos1.Delete(context.Background()))
+	require.NoError(t, chaos2.Delete(context.Background()))
+	rec2.requireEvent(t, "ended")
+	require.Eventually(t, func() bool {
+		return watchersOf(c) == 0
+	}, testEventTimeout, 10*time.Millisecond)
+}
+
+// nonComparableClient is a client that can't be a map key, its watches can't be shared
+type nonComparableClient struct {
+	client.WithWatch
+	namespaces []string
+}
+
+func TestChaosWatchOfNonComparableClient(t *testing.T) {
+	c := nonComparableClient{WithWatch: newFakeClient(t), namespaces: []string{testNamespace}}
+	rec := newEventRecorder()
+	chaos := newTestPodChaos(t, c, "pod-failure", rec)
+
+	chaos.Create(context.Background())
+	rec.requireEvent(t, "created")
+	setConditions(t, c, "pod-failure", map[v1alpha1.ChaosConditionType]corev1.ConditionStatus{
+		v1alpha1.ConditionSelected:    corev1.ConditionTrue,
+		v1alpha1.ConditionAllInjected: corev1.ConditionTrue,
+	})
+	rec.requireEvent(t, "started")
+	require.NoError(t, chaos.Delete(context.Background()))
+	rec.requireEvent(t, "ended")
+}
+
+// panickingListener panics when chaos is created and started
+type panickingListener struct {
+	eventRecorder
+}
+
+func (l *panickingListener) OnChaosCreated(chaos Chaos) { panic("listener failure") }
+func (l *panickingListener) OnChaosStarted(chaos Chaos) { panic("listener failure") }
+
+func TestChaosListenerPanicDoesNotAffectOthers(t *testing.T) {
+	c := newFakeClient(t)
+	rec := newEventRecorder()
+	chaos := newTestPodChaos(t, c, "pod-failure", &panickingListener{*newEventRecorder()}, rec)
+
+	chaos.Create(context.Background())
+	rec.requireEvent(t, "created")
+	setConditions(t, c, "pod-failure", map[v1alpha1.ChaosConditionType]corev1.ConditionStatus{
+		v1alpha1.ConditionSelected:    corev1.ConditionTrue,
+		v1alpha1.ConditionAllInjected: corev1.ConditionTrue,
+	})
+	rec.requireEvent(t, "started")
+	require.NoError(t, chaos.Delete(context.Background()))
+	rec.requireEvent(t, "ended")
+}
+
+// slowListener blocks until it's released, it records a snapshot of chaos status for every event
+type slowListener struct {
+	eventRecorder
+	release  chan struct{}
+	statuses chan ChaosStatus
+}
+
+func (l *slowListener) OnChaosStarted(chaos Chaos) {
+	<-l.release
+	l.statuses <- chaos.GetStatus()
+	l.events <- "started"
+}
+
+func (l *slowListener) OnChaosEnded(chaos Chaos) {
+	l.statuses <- chaos.GetStatus()
+	l.events <- "ended"
+}
+
+func TestChaosStateIsRaceFree(t *testing.T) {
+	c := newFakeClient(t)
+	slow := &slowListener{
+		eventRecorder: *newEventRecorder(),
+		release:       make(chan struct{}),
+		statuses:      make(chan ChaosStatus, 10),
+	}
+	chaos := newTestPodChaos(t, c, "pod-failure", slow)
+
+	// readers run concurrently with the monitor goroutine
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				_ = chaos.GetStatus()
+				_ = chaos.GetObject().GetResourceVersion()
+				_, _ = chaos.GetExperimentStatus()
+				_ = chaos.GetStartTime()
+				_ = chaos.GetEndTime()
+			}
+		}
+	}()
+
+	chaos.Create(context.Background())
+	slow.requireEvent(t, "created")
+	setConditions(t, c, "pod-failure", map[v1alpha1.ChaosConditionType]corev1.ConditionStatus{
+		v1alpha1.ConditionSelected:    corev1.ConditionTrue,
+		v1alpha1.ConditionAllInjected: corev1.ConditionTrue,
+	})
+	require.Eventually(t, func() bool {
+		return chaos.GetStatus() == StatusRunning
+	}, testEventTimeout, 10*time.Millisecond)
+
+	// the blocked listener doesn't block monitoring, events are delivered in order with their own snapshots
+	setConditions(t, c, "pod-failure", map[v1alpha1.ChaosConditionType]corev1.ConditionStatus{
+		v1alpha1.ConditionSelected:     corev1.ConditionTrue,
+		v1alpha1.ConditionAllRecovered: corev1.ConditionTrue,
+	})
+	require.Eventually(t, func() bool {
+		return chaos.GetStatus() == StatusDeleted
+	}, testEventTimeout, 10*time.Millisecond)
+	close(slow.release)
+	slow.requireEvent(t, "started")
+	slow.requireEvent(t, "ended")
+	require.Equal(t, StatusRunning, <-slow.statuses)
+	require.Equal(t, StatusFinished, <-slow.statuses)
+
+	close(stop)
+	<-done
+	chaos.dispatcher.wait()
+}
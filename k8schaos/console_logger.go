@@ -38,6 +38,11 @@ func (l ChaosLogger) OnChaosPaused(chaos Chaos) {
 		Msg("Chaos paused")
 }
 
+func (l ChaosLogger) OnChaosResumed(chaos Chaos) {
+	l.commonChaosLog("info", chaos).
+		Msg("Chaos resumed")
+}
+
 func (l ChaosLogger) OnChaosEnded(chaos Chaos) {
 	l.commonChaosLog("info", chaos).
 		Msg("Chaos ended")
@@ -91,6 +96,57 @@ func (l ChaosLogger) OnScheduleCreated(schedule Schedule) {
 		Msg("Chaos schedule created")
 }
 
+func (l ChaosLogger) OnScheduleCreationFailed(schedule Schedule, reason error) {
+	l.logger.Error().
+		Str("logger", "chaos").
+		Str("name", schedule.GetObject().GetName()).
+		Str("namespace", schedule.GetObject().GetNamespace()).
+		Str("description", schedule.GetChaosDescription()).
+		Err(reason).
+		Msg("Failed to create chaos schedule")
+}
+
+func (l ChaosLogger) OnSchedulePaused(schedule Schedule) {
+	l.logger.Info().
+		Str("logger", "chaos").
+		Str("name", schedule.GetObject().GetName()).
+		Str("namespace", schedule.GetObject().GetNamespace()).
+		Str("description", schedule.GetChaosDescription()).
+		Msg("Chaos schedule paused")
+}
+
+func (l ChaosLogger) OnScheduleResumed(schedule Schedule) {
+	l.logger.Info().
+		Str("logger", "chaos").
+		Str("name", schedule.GetObject().GetName()).
+		Str("namespace", schedule.GetObject().GetNamespace()).
+		Str("description", schedule.GetChaosDescription()).
+		Msg("Chaos schedule resumed")
+}
+
+func (l ChaosLogger) OnScheduledChaosStarted(schedule Schedule, spawned ScheduledChaos) {
+	l.logger.Info().
+		Str("logger", "chaos").
+		Str("schedule", schedule.GetObject().GetName()).
+		Str("kind", spawned.Kind).
+		Str("name", spawned.Name).
+		Str("namespace", spawned.Namespace).
+		Time("startTime", spawned.StartTime).
+		Msg("Scheduled chaos started")
+}
+
+func (l ChaosLogger) OnScheduledChaosEnded(schedule Schedule, spawned ScheduledChaos) {
+	l.logger.Info().
+		Str("logger", "chaos").
+		Str("schedule", schedule.GetObject().GetName()).
+		Str("kind", spawned.Kind).
+		Str("name", spawned.Name).
+		Str("namespace", spawned.Namespace).
+		Time("startTime", spawned.StartTime).
+		Time("endTime", spawned.EndTime).
+		Msg("Scheduled chaos ended")
+}
+
 func (l ChaosLogger) OnScheduleDeleted(schedule Schedule) {
 	duration, _ := schedule.GetChaosDuration()
 
@@ -0,0 +1,75 @@
+package k8schaos
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// newBuiltChaos wraps a built object into a ready to create Chaos, default package Logger is used if logger is nil
+func newBuiltChaos(obj client.Object, description string, delayCreate time.Duration, c client.Client, listeners []ChaosListener, logger *zerolog.Logger) (*Chaos, error) {
+	if obj.GetName() == "" {
+		return nil, errors.New("chaos name is required")
+	}
+	if obj.GetNamespace() == "" {
+		return nil, errors.New("chaos namespace is required")
+	}
+	if logger == nil {
+		logger = &Logger
+	}
+	return NewChaos(ChaosOpts{
+		Object:      obj,
+		Description: description,
+		DelayCreate: delayCreate,
+		Client:      c,
+		Listeners:   listeners,
+		Logger:      logger,
+	})
+}
+
+// NewNetworkChaos validates options, builds a NetworkChaos object and returns a Chaos ready to be created
+func NewNetworkChaos(opts NetworkChaosOpts) (*Chaos, error) {
+	obj, err := BuildNetworkChaos(opts)
+	if err != nil {
+		return nil, err
+	}
+	return newBuiltChaos(obj, opts.Description, opts.DelayCreate, opts.K8sClient, opts.Listeners, opts.Logger)
+}
+
+// NewPodChaos validates options, builds a PodChaos object and returns a Chaos ready to be created
+func NewPodChaos(opts PodChaosOpts) (*Chaos, error) {
+	obj, err := BuildPodChaos(opts)
+	if err != nil {
+		return nil, err
+	}
+	return newBuiltChaos(obj, opts.Description, opts.DelayCreate, opts.K8sClient, opts.Listeners, opts.Logger)
+}
+
+// NewStressChaos validates options, builds a StressChaos object and returns a Chaos ready to be created
+func NewStressChaos(opts StressChaosOpts) (*Chaos, error) {
+	obj, err := BuildStressChaos(opts)
+	if err != nil {
+		return nil, err
+	}
+	return newBuiltChaos(obj, opts.Description, opts.DelayCreate, opts.K8sClient, opts.Listeners, opts.Logger)
+}
+
+// NewHTTPChaos validates options, builds an HTTPChaos object and returns a Chaos ready to be created
+func NewHTTPChaos(opts HTTPChaosOpts) (*Chaos, error) {
+	obj, err := BuildHTTPChaos(opts)
+	if err != nil {
+		return nil, err
+	}
+	return newBuiltChaos(obj, opts.Description, opts.DelayCreate, opts.K8sClient, opts.Listeners, opts.Logger)
+}
+
+// NewIOChaos validates options, builds an IOChaos object and returns a Chaos ready to be created
+func NewIOChaos(opts IOChaosOpts) (*Chaos, error) {
+	obj, err := BuildIOChaos(opts)
+	if err != nil {
+		return nil, err
+	}
+	return newBuiltChaos(obj, opts.Description, opts.DelayCreate, opts.K8sClient, opts.Listeners, opts.Logger)
+}
@@ -0,0 +1,213 @@
+package k8schaos
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/chaos-mesh/chaos-mesh/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	testNamespace    = "chaos-test"
+	testEventTimeout = 5 * time.Second
+)
+
+var testSelector = v1alpha1.PodSelectorSpec{
+	GenericSelectorSpec: v1alpha1.GenericSelectorSpec{
+		Namespaces:     []string{testNamespace},
+		LabelSelectors: map[string]string{"app": "node"},
+	},
+}
+
+func newFakeClient(t *testing.T) client.WithWatch {
+	s := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(s))
+	return fake.NewClientBuilder().WithScheme(s).Build()
+}
+
+// requireCreated creates chaos and reads its object created in the cluster into obj, the object is deleted after the test
+func requireCreated(t *testing.T, c client.Client, chaos *Chaos, obj client.Object) {
+	key := client.ObjectKeyFromObject(chaos.GetObject())
+	chaos.Create(context.Background())
+	require.Eventually(t, func() bool {
+		return c.Get(context.Background(), key, obj) == nil
+	}, testEventTimeout, 10*time.Millisecond)
+	t.Cleanup(func() {
+		_ = c.Delete(context.Background(), obj)
+	})
+	require.Equal(t, key.Name, obj.GetName())
+	require.Equal(t, testNamespace, obj.GetNamespace())
+}
+
+func TestNewNetworkChaos(t *testing.T) {
+	c := newFakeClient(t)
+	opts := NetworkChaosOpts{
+		Name:      "latency",
+		Namespace: testNamespace,
+		NodeCount: 2,
+		Duration:  time.Minute,
+		Selector:  testSelector,
+		Delay:     &v1alpha1.DelaySpec{Latency: "300ms"},
+		K8sClient: c,
+	}
+	chaos, err := NewNetworkChaos(opts)
+	require.NoError(t, err)
+	obj := &v1alpha1.NetworkChaos{}
+	requireCreated(t, c, chaos, obj)
+	require.Equal(t, v1alpha1.DelayAction, obj.Spec.Action)
+	require.Equal(t, testSelector, obj.Spec.Selector)
+	require.Equal(t, v1alpha1.FixedMode, obj.Spec.Mode)
+	require.Equal(t, "2", obj.Spec.Value)
+	require.Equal(t, "1m", *obj.Spec.Duration)
+	require.Equal(t, "300ms", obj.Spec.Delay.Latency)
+
+	noName := opts
+	noName.Name = ""
+	_, err = NewNetworkChaos(noName)
+	require.EqualError(t, err, "chaos name is required")
+	noNamespace := opts
+	noNamespace.Namespace = ""
+	_, err = NewNetworkChaos(noNamespace)
+	require.EqualError(t, err, "chaos namespace is required")
+}
+
+func TestNewPodChaos(t *testing.T) {
+	c := newFakeClient(t)
+	spec := v1alpha1.PodChaosSpec{Action: v1alpha1.PodFailureAction}
+	spec.Selector = testSelector
+	chaos, err := NewPodChaos(PodChaosOpts{
+		Name:      "pod-failure",
+		Namespace: testNamespace,
+		Duration:  30 * time.Second,
+		Spec:      spec,
+		K8sClient: c,
+	})
+	require.NoError(t, err)
+	obj := &v1alpha1.PodChaos{}
+	requireCreated(t, c, chaos, obj)
+	require.Equal(t, v1alpha1.PodFailureAction, obj.Spec.Action)
+	require.Equal(t, testSelector, obj.Spec.Selector)
+	// all pods are affected without NodeCount
+	require.Equal(t, v1alpha1.AllMode, obj.Spec.Mode)
+	require.Empty(t, obj.Spec.Value)
+	require.Equal(t, "30s", *obj.Spec.Duration)
+
+	_, err = NewPodChaos(PodChaosOpts{Name: "pod-failure", Namespace: testNamespace, K8sClient: c})
+	require.ErrorContains(t, err, "action should be specified")
+}
+
+func TestNewStressChaos(t *testing.T) {
+	c := newFakeClient(t)
+	chaos, err := NewStressChaos(StressChaosOpts{
+		Name:           "cpu",
+		Namespace:      testNamespace,
+		NodeCount:      1,
+		Duration:       time.Hour,
+		Selector:       testSelector,
+		ContainerNames: []string{"node"},
+		Stressors:      &v1alpha1.Stressors{CPUStressor: &v1alpha1.CPUStressor{Stressor: v1alpha1.Stressor{Workers: 1}, Load: Ptr(100)}},
+		K8sClient:      c,
+	})
+	require.NoError(t, err)
+	obj := &v1alpha1.StressChaos{}
+	requireCreated(t, c, chaos, obj)
+	require.Equal(t, testSelector, obj.Spec.Selector)
+	require.Equal(t, []string{"node"}, obj.Spec.ContainerNames)
+	require.Equal(t, v1alpha1.FixedMode, obj.Spec.Mode)
+	require.Equal(t, "1", obj.Spec.Value)
+	require.Equal(t, "1h", *obj.Spec.Duration)
+	require.Equal(t, 100, *obj.Spec.Stressors.CPUStressor.Load)
+
+	_, err = NewStressChaos(StressChaosOpts{Name: "cpu", Namespace: testNamespace, Stressors: &v1alpha1.Stressors{}, K8sClient: c})
+	require.ErrorContains(t, err, "either CPU or memory stressor should be specified")
+}
+
+func TestNewHTTPChaos(t *testing.T) {
+	c := newFakeClient(t)
+	opts := HTTPChaosOpts{
+		Name:      "http",
+		Namespace: testNamespace,
+		Mode:      v1alpha1.FixedPercentMode,
+		ModeValue: "50",
+		NodeCount: 3,
+		Duration:  10 * time.Second,
+		Selector:  testSelector,
+		Target:    v1alpha1.PodHttpRequest,
+		Port:      8080,
+		Path:      "/pets",
+		Method:    "GET",
+		Abort:     true,
+		K8sClient: c,
+	}
+	chaos, err := NewHTTPChaos(opts)
+	require.NoError(t, err)
+	obj := &v1alpha1.HTTPChaos{}
+	requireCreated(t, c, chaos, obj)
+	require.Equal(t, testSelector, obj.Spec.Selector)
+	// explicit mode takes precedence over NodeCount
+	require.Equal(t, v1alpha1.FixedPercentMode, obj.Spec.Mode)
+	require.Equal(t, "50", obj.Spec.Value)
+	require.Equal(t, "10s", *obj.Spec.Duration)
+	require.Equal(t, int32(8080), obj.Spec.Port)
+	require.Equal(t, "/pets", *obj.Spec.Path)
+	require.Equal(t, "GET", *obj.Spec.Method)
+	require.True(t, *obj.Spec.Abort)
+
+	invalid := map[string]func(o *HTTPChaosOpts){
+		"target should be Request or Response":      func(o *HTTPChaosOpts) { o.Target = "Both" },
+		"port should be specified":                  func(o *HTTPChaosOpts) { o.Port = 0 },
+		"either abort or delay should be specified": func(o *HTTPChaosOpts) { o.Abort = false },
+	}
+	for msg, modify := range invalid {
+		o := opts
+		modify(&o)
+		_, err := NewHTTPChaos(o)
+		require.ErrorContains(t, err, msg)
+	}
+}
+
+func TestNewIOChaos(t *testing.T) {
+	c := newFakeClient(t)
+	opts := IOChaosOpts{
+		Name:       "io-latency",
+		Namespace:  testNamespace,
+		NodeCount:  1,
+		Duration:   time.Minute,
+		Selector:   testSelector,
+		Action:     v1alpha1.IoLatency,
+		VolumePath: "/data",
+		Delay:      "100ms",
+		Percent:    50,
+		K8sClient:  c,
+	}
+	chaos, err := NewIOChaos(opts)
+	require.NoError(t, err)
+	obj := &v1alpha1.IOChaos{}
+	requireCreated(t, c, chaos, obj)
+	require.Equal(t, testSelector, obj.Spec.Selector)
+	require.Equal(t, v1alpha1.FixedMode, obj.Spec.Mode)
+	require.Equal(t, "1", obj.Spec.Value)
+	require.Equal(t, "1m", *obj.Spec.Duration)
+	require.Equal(t, v1alpha1.IoLatency, obj.Spec.Action)
+	require.Equal(t, "/data", obj.Spec.VolumePath)
+	require.Equal(t, "100ms", obj.Spec.Delay)
+
+	invalid := map[string]func(o *IOChaosOpts){
+		"volume path should be specified":     func(o *IOChaosOpts) { o.VolumePath = "" },
+		"invalid delay":                       func(o *IOChaosOpts) { o.Delay = "slow" },
+		"errno should be specified":           func(o *IOChaosOpts) { o.Action = v1alpha1.IoFaults },
+		"action should be latency or fault":   func(o *IOChaosOpts) { o.Action = v1alpha1.IoMistake },
+		"percent should be between 0 and 100": func(o *IOChaosOpts) { o.Percent = 101 },
+	}
+	for msg, modify := range invalid {
+		o := opts
+		modify(&o)
+		_, err := NewIOChaos(o)
+		require.ErrorContains(t, err, msg)
+	}
+}
@@ -0,0 +1,182 @@
+package k8schaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/rs/zerolog"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ChaosGroup orchestrates a set of chaos experiments and schedules as one composite fault:
+// they are created together with their own DelayCreate offsets, awaited together and deleted together
+type ChaosGroup struct {
+	Name     string
+	entities []ChaosEntity
+	logger   *zerolog.Logger
+	mu       sync.Mutex
+	cancel   context.CancelFunc
+	// skipped are entities by index that were deleted before they were created, they have no lifecycle events
+	skipped []bool
+}
+
+type ChaosGroupOpts struct {
+	Name     string
+	Entities []ChaosEntity
+	Logger   *zerolog.Logger
+}
+
+func NewChaosGroup(opts ChaosGroupOpts) (*ChaosGroup, error) {
+	if len(opts.Entities) == 0 {
+		return nil, errors.New("at least one chaos entity is required")
+	}
+	if opts.Logger == nil {
+		opts.Logger = &Logger
+	}
+	for _, e := range opts.Entities {
+		if e == nil {
+			return nil, errors.New("chaos entity is nil")
+		}
+	}
+	return &ChaosGroup{
+		Name:     opts.Name,
+		entities: opts.Entities,
+		logger:   opts.Logger,
+		skipped:  make([]bool, len(opts.Entities)),
+	}, nil
+}
+
+func chaosEntityName(e ChaosEntity) string {
+	return client.ObjectKeyFromObject(e.GetObject()).String()
+}
+
+// Create creates all entities, every entity is created after its own DelayCreate.
+// Entities that are not created yet are skipped if the context is canceled or the group is deleted
+func (g *ChaosGroup) Create(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	g.mu.Lock()
+	g.cancel = cancel
+	g.mu.Unlock()
+	g.logger.Info().Str("group", g.Name).Int("entities", len(g.entities)).Msg("Creating chaos group")
+	for _, e := range g.entities {
+		e.Create(ctx)
+	}
+}
+
+// WaitForRunning waits until all entities are running, schedules are running once they are created.
+// All entities are deleted if any of them fails to be created or ctx is done before they are running
+func (g *ChaosGroup) WaitForRunning(ctx context.Context) error {
+	if err := WaitForStatus(ctx, StatusRunning, g.entities...); err != nil {
+		return g.abort(fmt.Errorf("chaos group %q is not running: %w", g.Name, err))
+	}
+	return nil
+}
+
+// WaitForEnded waits until all entities are finished or deleted, all entities are deleted on error
+func (g *ChaosGroup) WaitForEnded(ctx context.Context) error {
+	if err := WaitForEnded(ctx, g.createdEntities()...); err != nil {
+		return g.abort(fmt.Errorf("chaos group %q is not ended: %w", g.Name, err))
+	}
+	return nil
+}
+
+// createdEntities returns entities that were not skipped by deletion before they were created
+func (g *ChaosGroup) createdEntities() []ChaosEntity {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	entities := make([]ChaosEntity, 0, len(g.entities))
+	for i, e := range g.entities {
+		if !g.skipped[i] {
+			entities = append(entities, e)
+		}
+	}
+	return entities
+}
+
+// abort deletes all entities and returns err with deletion errors
+func (g *ChaosGroup) abort(err error) error {
+	g.logger.Error().Err(err).Str("group", g.Name).Msg("Deleting chaos group")
+	if delErr := g.Delete(context.Background()); delErr != nil {
+		return errors.Join(err, delErr)
+	}
+	return err
+}
+
+// Delete cancels pending creations and deletes all created entities, errors of all entities are returned together
+func (g *ChaosGroup) Delete(ctx context.Context) error {
+	g.mu.Lock()
+	cancel := g.cancel
+	g.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+
+	var errs []error
+	for i, e := range g.entities {
+		status := e.GetStatus()
+		if status == StatusCreationFailed || status == StatusDeleted {
+			continue
+		}
+		err := e.Delete(ctx)
+		if client.IgnoreNotFound(err) != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", chaosEntityName(e), err))
+			continue
+		}
+		// entities that were not created are not found, their creation was canceled above
+		if err != nil && status == "" {
+			g.mu.Lock()
+			g.skipped[i] = true
+			g.mu.Unlock()
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Statuses returns status of every entity by namespaced name
+func (g *ChaosGroup) Statuses() map[string]ChaosStatus {
+	statuses := make(map[string]ChaosStatus)
+	for i, e := range g.entities {
+		statuses[chaosEntityName(e)] = g.status(i)
+	}
+	return statuses
+}
+
+// Status returns aggregated group status: the status of all entities if it's the same,
+// creation failure if any entity failed to be created, or unknown otherwise
+func (g *ChaosGroup) Status() ChaosStatus {
+	status := g.status(0)
+	for i := range g.entities {
+		s := g.status(i)
+		if s == StatusCreationFailed {
+			return StatusCreationFailed
+		}
+		if s != status {
+			status = StatusUnknown
+		}
+	}
+	return status
+}
+
+// status returns entity status, entities deleted before they were created are deleted
+func (g *ChaosGroup) status(i int) ChaosStatus {
+	g.mu.Lock()
+	skipped := g.skipped[i]
+	g.mu.Unlock()
+	if skipped {
+		return StatusDeleted
+	}
+	return g.entities[i].GetStatus()
+}
+
+// Err returns errors of all entities that failed to be created
+func (g *ChaosGroup) Err() error {
+	var errs []error
+	for _, e := range g.entities {
+		if err := entityCreationError(e); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", chaosEntityName(e), err))
+		}
+	}
+	return errors.Join(errs...)
+}
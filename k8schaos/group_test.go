@@ -0,0 +1,121 @@
+package k8schaos
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/chaos-mesh/chaos-mesh/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var injected = map[v1alpha1.ChaosConditionType]corev1.ConditionStatus{
+	v1alpha1.ConditionSelected:    corev1.ConditionTrue,
+	v1alpha1.ConditionAllInjected: corev1.ConditionTrue,
+}
+
+func podChaosExists(t *testing.T, c client.Client, name string) bool {
+	exists, err := ChaosObjectExists(&v1alpha1.PodChaos{ObjectMeta: objectMeta(name, testNamespace)}, c)
+	require.NoError(t, err)
+	return exists
+}
+
+// injectWhenCreated sets injected conditions on the chaos object once it's created, like Chaos Mesh controller
+func injectWhenCreated(t *testing.T, c client.Client, name string) {
+	require.Eventually(t, func() bool {
+		return podChaosExists(t, c, name)
+	}, testEventTimeout, 10*time.Millisecond)
+	setConditions(t, c, name, injected)
+}
+
+func TestChaosGroupLifecycle(t *testing.T) {
+	c := newFakeClient(t)
+	first := newTestPodChaos(t, c, "pod-failure-1")
+	second := newTestPodChaos(t, c, "pod-failure-2")
+	second.DelayCreate = 100 * time.Millisecond
+	g, err := NewChaosGroup(ChaosGroupOpts{Name: "composite", Entities: []ChaosEntity{first, second}})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testEventTimeout)
+	defer cancel()
+	g.Create(ctx)
+	require.False(t, podChaosExists(t, c, "pod-failure-2"), "second chaos must be created after its delay")
+	injectWhenCreated(t, c, "pod-failure-1")
+	injectWhenCreated(t, c, "pod-failure-2")
+	require.NoError(t, g.WaitForRunning(ctx))
+	require.Equal(t, StatusRunning, g.Status())
+	require.Equal(t, map[string]ChaosStatus{
+		testNamespace + "/pod-failure-1": StatusRunning,
+		testNamespace + "/pod-failure-2": StatusRunning,
+	}, g.Statuses())
+
+	require.NoError(t, g.Delete(ctx))
+	require.NoError(t, g.WaitForEnded(ctx))
+	require.NoError(t, g.Err())
+	require.False(t, podChaosExists(t, c, "pod-failure-1"))
+	require.False(t, podChaosExists(t, c, "pod-failure-2"))
+}
+
+func TestChaosGroupDeletesAllOnCreationFailure(t *testing.T) {
+	c := newFakeClient(t)
+	// the second chaos can't be created because an object with its name exists
+	require.NoError(t, c.Create(context.Background(), &v1alpha1.PodChaos{ObjectMeta: objectMeta("pod-failure-2", testNamespace)}))
+	first := newTestPodChaos(t, c, "pod-failure-1")
+	second := newTestPodChaos(t, c, "pod-failure-2")
+	second.DelayCreate = 100 * time.Millisecond
+	g, err := NewChaosGroup(ChaosGroupOpts{Name: "composite", Entities: []ChaosEntity{first, second}})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testEventTimeout)
+	defer cancel()
+	g.Create(ctx)
+	injectWhenCreated(t, c, "pod-failure-1")
+	err = g.WaitForRunning(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), testNamespace+"/pod-failure-2")
+	require.Equal(t, StatusCreationFailed, g.Status())
+	require.False(t, podChaosExists(t, c, "pod-failure-1"))
+}
+
+func TestChaosGroupFailsWhenMemberEndedBeforeRunning(t *testing.T) {
+	c := newFakeClient(t)
+	first := newTestPodChaos(t, c, "pod-failure-1")
+	second := newTestPodChaos(t, c, "pod-failure-2")
+	g, err := NewChaosGroup(ChaosGroupOpts{Name: "composite", Entities: []ChaosEntity{first, second}})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testEventTimeout)
+	defer cancel()
+	g.Create(ctx)
+	injectWhenCreated(t, c, "pod-failure-1")
+	setConditions(t, c, "pod-failure-1", map[v1alpha1.ChaosConditionType]corev1.ConditionStatus{
+		v1alpha1.ConditionSelected:     corev1.ConditionTrue,
+		v1alpha1.ConditionAllRecovered: corev1.ConditionTrue,
+	})
+	// the second chaos is never injected, the group can't be running because the first one has ended
+	err = g.WaitForRunning(ctx)
+	require.Error(t, err)
+	require.NotErrorIs(t, err, context.DeadlineExceeded)
+	require.Contains(t, err.Error(), testNamespace+"/pod-failure-1: chaos is")
+	require.False(t, podChaosExists(t, c, "pod-failure-2"))
+}
+
+func TestChaosGroupDeletesAllOnCancel(t *testing.T) {
+	c := newFakeClient(t)
+	first := newTestPodChaos(t, c, "pod-failure-1")
+	second := newTestPodChaos(t, c, "pod-failure-2")
+	second.DelayCreate = time.Hour
+	g, err := NewChaosGroup(ChaosGroupOpts{Name: "composite", Entities: []ChaosEntity{first, second}})
+	require.NoError(t, err)
+
+	g.Create(context.Background())
+	injectWhenCreated(t, c, "pod-failure-1")
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	err = g.WaitForRunning(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.False(t, podChaosExists(t, c, "pod-failure-1"))
+	require.False(t, podChaosExists(t, c, "pod-failure-2"))
+}
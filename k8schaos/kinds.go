@@ -0,0 +1,55 @@
+package k8schaos
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/chaos-mesh/chaos-mesh/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// lookupKind returns kind name of a Chaos Mesh object from the v1alpha1 kinds registry,
+// so every kind registered by Chaos Mesh, including schedules and workflows, is supported
+func lookupKind(obj client.Object) (string, *v1alpha1.ChaosKind, error) {
+	t := reflect.TypeOf(obj)
+	if t == nil || t.Kind() != reflect.Ptr {
+		return "", nil, fmt.Errorf("unsupported chaos object type: %T", obj)
+	}
+	kind, ok := v1alpha1.AllKindsIncludeScheduleAndWorkflow()[t.Elem().Name()]
+	if !ok {
+		return "", nil, fmt.Errorf("unsupported chaos object type: %T", obj)
+	}
+	return t.Elem().Name(), kind, nil
+}
+
+// specValue returns addressable Spec field of a Chaos Mesh object, all kinds keep their spec in it
+func specValue(obj client.Object) (reflect.Value, error) {
+	if _, _, err := lookupKind(obj); err != nil {
+		return reflect.Value{}, err
+	}
+	v := reflect.ValueOf(obj)
+	if v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("unsupported chaos object type: %T", obj)
+	}
+	spec := v.Elem().FieldByName("Spec")
+	if !spec.IsValid() {
+		return reflect.Value{}, fmt.Errorf("chaos object %T has no spec", obj)
+	}
+	return spec, nil
+}
+
+// specDuration returns duration of the chaos spec, specs of all chaos kinds parse it with GetDuration
+func specDuration(obj client.Object) (*time.Duration, error) {
+	spec, err := specValue(obj)
+	if err != nil {
+		return nil, err
+	}
+	withDuration, ok := spec.Addr().Interface().(interface {
+		GetDuration() (*time.Duration, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("chaos object %T has no duration", obj)
+	}
+	return withDuration.GetDuration()
+}
@@ -0,0 +1,83 @@
+package k8schaos
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/chaos-mesh/chaos-mesh/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestChaosSupportsAllKinds(t *testing.T) {
+	c := newFakeClient(t)
+	for name, kind := range v1alpha1.AllKinds() {
+		obj := kind.SpawnObject()
+		chaos, err := NewChaos(ChaosOpts{Object: obj, Client: c, Logger: &Logger})
+		require.NoError(t, err)
+		chaosKind, err := chaos.GetChaosKind()
+		require.NoError(t, err, name)
+		require.Equal(t, name, chaosKind)
+		require.Equal(t, name, chaos.GetChaosTypeStr())
+		require.NotNil(t, chaos.GetChaosSpec(), name)
+		_, err = chaos.GetChaosStatus()
+		require.NoError(t, err, name)
+	}
+}
+
+func TestChaosUnsupportedKind(t *testing.T) {
+	chaos, err := NewChaos(ChaosOpts{
+		Object: &corev1.Pod{ObjectMeta: objectMeta("pod", testNamespace)},
+		Client: newFakeClient(t),
+		Logger: &Logger,
+	})
+	require.NoError(t, err)
+	_, err = chaos.GetChaosKind()
+	require.Error(t, err)
+	require.Equal(t, "Unknown", chaos.GetChaosTypeStr())
+	require.Nil(t, chaos.GetChaosSpec())
+	_, err = chaos.GetChaosDuration()
+	require.Error(t, err)
+	_, err = chaos.GetChaosStatus()
+	require.Error(t, err)
+	_, err = ChaosObjectExists(chaos.GetObject(), chaos.Client)
+	require.Error(t, err)
+}
+
+func TestTimeChaosLifecycle(t *testing.T) {
+	c := newFakeClient(t)
+	rec := newEventRecorder()
+	duration := "1m"
+	chaos, err := NewChaos(ChaosOpts{
+		Object: &v1alpha1.TimeChaos{
+			ObjectMeta: objectMeta("time-offset", testNamespace),
+			Spec: v1alpha1.TimeChaosSpec{
+				ContainerSelector: v1alpha1.ContainerSelector{PodSelector: v1alpha1.PodSelector{Mode: v1alpha1.AllMode}},
+				TimeOffset:        "-10m",
+				Duration:          &duration,
+			},
+		},
+		Client:    c,
+		Listeners: []ChaosListener{rec},
+		Logger:    &Logger,
+	})
+	require.NoError(t, err)
+	d, err := chaos.GetChaosDuration()
+	require.NoError(t, err)
+	require.Equal(t, time.Minute, d)
+
+	chaos.Create(context.Background())
+	rec.requireEvent(t, "created")
+	obj := &v1alpha1.TimeChaos{}
+	require.NoError(t, c.Get(context.Background(), client.ObjectKey{Namespace: testNamespace, Name: "time-offset"}, obj))
+	obj.Status.Conditions = []v1alpha1.ChaosCondition{
+		{Type: v1alpha1.ConditionSelected, Status: corev1.ConditionTrue},
+		{Type: v1alpha1.ConditionAllInjected, Status: corev1.ConditionTrue},
+	}
+	require.NoError(t, c.Update(context.Background(), obj))
+	rec.requireEvent(t, "started")
+	require.NoError(t, chaos.Delete(context.Background()))
+	rec.requireEvent(t, "ended")
+}
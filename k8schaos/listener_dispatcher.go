@@ -0,0 +1,97 @@
+package k8schaos
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// listenerEvent is a lifecycle event delivered to listeners registered when it happened
+type listenerEvent struct {
+	name      string
+	listeners []ChaosListener
+	call      func(listener ChaosListener)
+}
+
+// listenerDispatcher delivers events to listeners asynchronously in the order they were dispatched,
+// so listeners never block chaos monitoring, a panicking listener is logged and doesn't affect other listeners
+type listenerDispatcher struct {
+	mu      sync.Mutex
+	queue   []listenerEvent
+	running bool
+	idle    *sync.Cond
+	logger  *zerolog.Logger
+}
+
+// removeListener returns listeners without the listener, listeners are compared by identity
+func removeListener(listeners []ChaosListener, listener ChaosListener) []ChaosListener {
+	out := make([]ChaosListener, 0, len(listeners))
+	for _, l := range listeners {
+		if l != listener {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+func newListenerDispatcher(logger *zerolog.Logger) *listenerDispatcher {
+	d := &listenerDispatcher{logger: logger}
+	d.idle = sync.NewCond(&d.mu)
+	return d
+}
+
+// dispatch queues the event, events are delivered by a single goroutine that exits when the queue is empty
+func (d *listenerDispatcher) dispatch(name string, listeners []ChaosListener, call func(listener ChaosListener)) {
+	if len(listeners) == 0 {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.queue = append(d.queue, listenerEvent{name: name, listeners: listeners, call: call})
+	if !d.running {
+		d.running = true
+		go d.deliverAll()
+	}
+}
+
+func (d *listenerDispatcher) deliverAll() {
+	for {
+		d.mu.Lock()
+		if len(d.queue) == 0 {
+			d.running = false
+			d.idle.Broadcast()
+			d.mu.Unlock()
+			return
+		}
+		e := d.queue[0]
+		d.queue = d.queue[1:]
+		d.mu.Unlock()
+
+		for _, l := range e.listeners {
+			d.deliver(l, e)
+		}
+	}
+}
+
+func (d *listenerDispatcher) deliver(listener ChaosListener, e listenerEvent) {
+	defer func() {
+		if r := recover(); r != nil {
+			d.logger.Error().
+				Interface("panic", r).
+				Str("event", e.name).
+				Str("listener", fmt.Sprintf("%T", listener)).
+				Msg("Chaos listener panicked")
+		}
+	}()
+	e.call(listener)
+}
+
+// wait blocks until all dispatched events are delivered
+func (d *listenerDispatcher) wait() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for d.running {
+		d.idle.Wait()
+	}
+}
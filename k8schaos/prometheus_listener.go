@@ -0,0 +1,244 @@
+package k8schaos
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"github.com/rs/zerolog"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PrometheusListener is a ChaosListener exposing chaos lifecycle as Prometheus metrics, so chaos can be shown
+// on the same dashboards as service metrics. Metrics are registered on the registry of the caller
+// and pushed to a Pushgateway in the background after events if its URL is set, Close pushes them for the last time
+type PrometheusListener struct {
+	active           *prometheus.GaugeVec
+	creationFailures *prometheus.CounterVec
+	injectionLatency *prometheus.HistogramVec
+	duration         *prometheus.HistogramVec
+	pusher           *push.Pusher
+	pushRequests     chan struct{}
+	stopPushes       chan struct{}
+	pushDone         chan struct{}
+	closeOnce        sync.Once
+	logger           *zerolog.Logger
+	mu               sync.Mutex
+	createdAt        map[string]time.Time
+}
+
+type PrometheusListenerOpts struct {
+	Registry *prometheus.Registry
+	// Namespace prefixes metric names, "k8schaos" is used if it's empty
+	Namespace string
+	// PushgatewayURL enables pushing all metrics of the registry as PushJob, "k8schaos" is used if PushJob is empty
+	PushgatewayURL string
+	PushJob        string
+	// PushTimeout limits every push, 10s is used if it's zero
+	PushTimeout time.Duration
+	Logger      *zerolog.Logger
+}
+
+func NewPrometheusListener(opts PrometheusListenerOpts) (*PrometheusListener, error) {
+	if opts.Registry == nil {
+		return nil, errors.New("prometheus registry is required")
+	}
+	if opts.Namespace == "" {
+		opts.Namespace = "k8schaos"
+	}
+	if opts.PushJob == "" {
+		opts.PushJob = "k8schaos"
+	}
+	if opts.PushTimeout == 0 {
+		opts.PushTimeout = 10 * time.Second
+	}
+	if opts.Logger == nil {
+		opts.Logger = &Logger
+	}
+	l := &PrometheusListener{
+		active: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: opts.Namespace,
+			Name:      "chaos_active",
+			Help:      "Whether faults of the chaos are injected: 1 since the chaos is started or resumed, 0 while it's paused, the series is deleted when it's ended",
+		}, []string{"kind", "name", "namespace"}),
+		creationFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: opts.Namespace,
+			Name:      "chaos_creation_failures_total",
+			Help:      "Number of chaos objects and schedules that failed to be created",
+		}, []string{"kind"}),
+		injectionLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: opts.Namespace,
+			Name:      "chaos_injection_latency_seconds",
+			Help:      "Time from chaos creation until its faults are injected",
+			Buckets:   []float64{0.5, 1, 2, 5, 10, 30, 60, 120},
+		}, []string{"kind"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: opts.Namespace,
+			Name:      "chaos_duration_seconds",
+			Help:      "Time from chaos start until it's ended",
+			Buckets:   []float64{10, 30, 60, 300, 600, 1800, 3600},
+		}, []string{"kind"}),
+		logger:    opts.Logger,
+		createdAt: make(map[string]time.Time),
+	}
+	for _, c := range []prometheus.Collector{l.active, l.creationFailures, l.injectionLatency, l.duration} {
+		if err := opts.Registry.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	if opts.PushgatewayURL != "" {
+		l.pusher = push.New(opts.PushgatewayURL, opts.PushJob).
+			Gatherer(opts.Registry).
+			Client(&http.Client{Timeout: opts.PushTimeout})
+		l.pushRequests = make(chan struct{}, 1)
+		l.stopPushes = make(chan struct{})
+		l.pushDone = make(chan struct{})
+		go l.pushLoop()
+	}
+	return l, nil
+}
+
+// Push pushes all metrics of the registry to the Pushgateway, it does nothing if Pushgateway URL is not set
+func (l *PrometheusListener) Push() error {
+	if l.pusher == nil {
+		return nil
+	}
+	return l.pusher.Push()
+}
+
+// Close stops background pushes and pushes metrics for the last time, so the end of the last chaos is not lost,
+// it does nothing if Pushgateway URL is not set
+func (l *PrometheusListener) Close() error {
+	if l.pusher == nil {
+		return nil
+	}
+	l.closeOnce.Do(func() {
+		close(l.stopPushes)
+		<-l.pushDone
+	})
+	return l.Push()
+}
+
+// pushLoop pushes metrics off the listener goroutine, events that arrive while a push is in flight are coalesced into the next push
+func (l *PrometheusListener) pushLoop() {
+	defer close(l.pushDone)
+	for {
+		select {
+		case <-l.pushRequests:
+			if err := l.Push(); err != nil {
+				l.logger.Error().Err(err).Msg("Failed to push chaos metrics")
+			}
+		case <-l.stopPushes:
+			return
+		}
+	}
+}
+
+// push requests a background push, it never blocks event delivery
+func (l *PrometheusListener) push() {
+	if l.pusher == nil {
+		return
+	}
+	select {
+	case l.pushRequests <- struct{}{}:
+	default:
+	}
+}
+
+func (l *PrometheusListener) setActive(chaos Chaos, active bool) {
+	value := 0.0
+	if active {
+		value = 1
+	}
+	obj := chaos.GetObject()
+	l.active.WithLabelValues(chaos.GetChaosTypeStr(), obj.GetName(), obj.GetNamespace()).Set(value)
+}
+
+func (l *PrometheusListener) OnChaosCreated(chaos Chaos) {
+	// the event is delivered asynchronously, the creation time of the object is used if API server set it
+	createdAt := chaos.GetObject().GetCreationTimestamp().Time
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	l.mu.Lock()
+	l.createdAt[client.ObjectKeyFromObject(chaos.GetObject()).String()] = createdAt
+	l.mu.Unlock()
+	l.setActive(chaos, false)
+	l.push()
+}
+
+func (l *PrometheusListener) OnChaosCreationFailed(chaos Chaos, reason error) {
+	l.creationFailures.WithLabelValues(chaos.GetChaosTypeStr()).Inc()
+	l.push()
+}
+
+func (l *PrometheusListener) OnChaosStarted(chaos Chaos) {
+	key := client.ObjectKeyFromObject(chaos.GetObject()).String()
+	l.mu.Lock()
+	createdAt, ok := l.createdAt[key]
+	delete(l.createdAt, key)
+	l.mu.Unlock()
+	if ok && !chaos.GetStartTime().IsZero() {
+		l.injectionLatency.WithLabelValues(chaos.GetChaosTypeStr()).Observe(chaos.GetStartTime().Sub(createdAt).Seconds())
+	}
+	l.setActive(chaos, true)
+	l.push()
+}
+
+func (l *PrometheusListener) OnChaosPaused(chaos Chaos) {
+	l.setActive(chaos, false)
+	l.push()
+}
+
+func (l *PrometheusListener) OnChaosResumed(chaos Chaos) {
+	l.setActive(chaos, true)
+	l.push()
+}
+
+func (l *PrometheusListener) OnChaosEnded(chaos Chaos) {
+	l.mu.Lock()
+	delete(l.createdAt, client.ObjectKeyFromObject(chaos.GetObject()).String())
+	l.mu.Unlock()
+	// chaos that was never started has no duration
+	if !chaos.GetStartTime().IsZero() && !chaos.GetEndTime().IsZero() {
+		l.duration.WithLabelValues(chaos.GetChaosTypeStr()).Observe(chaos.GetEndTime().Sub(chaos.GetStartTime()).Seconds())
+	}
+	// ended chaos won't be active again, its series is deleted, so names of short-lived chaos don't pile up
+	obj := chaos.GetObject()
+	l.active.DeleteLabelValues(chaos.GetChaosTypeStr(), obj.GetName(), obj.GetNamespace())
+	l.push()
+}
+
+func (l *PrometheusListener) OnChaosStatusUnknown(chaos Chaos) {
+}
+
+func (l *PrometheusListener) OnScheduleCreated(s Schedule) {
+}
+
+func (l *PrometheusListener) OnScheduleCreationFailed(s Schedule, reason error) {
+	l.creationFailures.WithLabelValues("Schedule").Inc()
+	l.push()
+}
+
+func (l *PrometheusListener) OnSchedulePaused(s Schedule) {
+}
+
+func (l *PrometheusListener) OnScheduleResumed(s Schedule) {
+}
+
+func (l *PrometheusListener) OnScheduledChaosStarted(s Schedule, spawned ScheduledChaos) {
+	l.active.WithLabelValues(spawned.Kind, spawned.Name, spawned.Namespace).Set(1)
+	l.push()
+}
+
+func (l *PrometheusListener) OnScheduledChaosEnded(s Schedule, spawned ScheduledChaos) {
+	l.active.DeleteLabelValues(spawned.Kind, spawned.Name, spawned.Namespace)
+	l.duration.WithLabelValues(spawned.Kind).Observe(spawned.EndTime.Sub(spawned.StartTime).Seconds())
+	l.push()
+}
+
+func (l *PrometheusListener) OnScheduleDeleted(s Schedule) {
+}
@@ -0,0 +1,138 @@
+package k8schaos
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chaos-mesh/chaos-mesh/api/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// pushgateway is a local Pushgateway recording bodies of pushes
+type pushgateway struct {
+	mu     sync.Mutex
+	paths  []string
+	bodies []string
+}
+
+func (p *pushgateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	p.mu.Lock()
+	p.paths = append(p.paths, r.URL.Path)
+	p.bodies = append(p.bodies, string(body))
+	p.mu.Unlock()
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestPrometheusListener(t *testing.T) {
+	gateway := &pushgateway{}
+	server := httptest.NewServer(gateway)
+	defer server.Close()
+
+	registry := prometheus.NewRegistry()
+	listener, err := NewPrometheusListener(PrometheusListenerOpts{
+		Registry:       registry,
+		PushgatewayURL: server.URL,
+		PushJob:        "chaos-test",
+	})
+	require.NoError(t, err)
+	c := newFakeClient(t)
+	chaos := newTestPodChaos(t, c, "pod-failure", listener)
+	rec := newEventRecorder()
+	chaos.AddListener(rec)
+
+	chaos.Create(context.Background())
+	rec.requireEvent(t, "created")
+	injectWhenCreated(t, c, "pod-failure")
+	rec.requireEvent(t, "started")
+	chaos.dispatcher.wait()
+	require.Equal(t, 1.0, testutil.ToFloat64(listener.active.WithLabelValues("PodChaos", "pod-failure", testNamespace)))
+	require.Equal(t, 1, testutil.CollectAndCount(listener.injectionLatency))
+
+	setConditions(t, c, "pod-failure", map[v1alpha1.ChaosConditionType]corev1.ConditionStatus{
+		v1alpha1.ConditionSelected:     corev1.ConditionTrue,
+		v1alpha1.ConditionAllRecovered: corev1.ConditionTrue,
+	})
+	rec.requireEvent(t, "ended")
+	chaos.dispatcher.wait()
+	// WithLabelValues would create the series again, so series are counted instead
+	require.Equal(t, 0, testutil.CollectAndCount(listener.active))
+	require.Equal(t, 1, testutil.CollectAndCount(listener.duration))
+
+	// the existing object can't be created again
+	failed := newTestPodChaos(t, c, "pod-failure-2", listener)
+	failed.AddListener(rec)
+	require.NoError(t, c.Create(context.Background(), failed.GetObject().DeepCopyObject().(*v1alpha1.PodChaos)))
+	failed.Create(context.Background())
+	rec.requireEvent(t, "creation_failed")
+	failed.dispatcher.wait()
+	require.Equal(t, 1.0, testutil.ToFloat64(listener.creationFailures.WithLabelValues("PodChaos")))
+
+	// pushes are done in the background, Close pushes the last state
+	require.NoError(t, listener.Close())
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+	require.NotEmpty(t, gateway.paths)
+	require.Equal(t, "/metrics/job/chaos-test", gateway.paths[0])
+	require.True(t, strings.Contains(gateway.bodies[len(gateway.bodies)-1], "k8schaos_chaos_creation_failures_total"))
+}
+
+func TestPrometheusListenerSlowPushgateway(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	listener, err := NewPrometheusListener(PrometheusListenerOpts{
+		Registry:       prometheus.NewRegistry(),
+		PushgatewayURL: server.URL,
+		PushTimeout:    200 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	spawned := ScheduledChaos{Kind: "PodChaos", Name: "pod-failure-spawned", Namespace: testNamespace, StartTime: time.Now()}
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		listener.OnScheduledChaosStarted(Schedule{}, spawned)
+	}
+	// events are not delayed by pushes to a hanging Pushgateway
+	require.Less(t, time.Since(start), 100*time.Millisecond)
+	// the last push is bounded by the push timeout
+	require.Error(t, listener.Close())
+	require.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestPrometheusListenerScheduledChaos(t *testing.T) {
+	listener, err := NewPrometheusListener(PrometheusListenerOpts{Registry: prometheus.NewRegistry()})
+	require.NoError(t, err)
+	spawned := ScheduledChaos{Kind: "PodChaos", Name: "pod-failure-spawned", Namespace: testNamespace, StartTime: time.Now()}
+	listener.OnScheduledChaosStarted(Schedule{}, spawned)
+	require.Equal(t, 1, testutil.CollectAndCount(listener.active))
+
+	spawned.EndTime = spawned.StartTime.Add(time.Minute)
+	listener.OnScheduledChaosEnded(Schedule{}, spawned)
+	require.Equal(t, 0, testutil.CollectAndCount(listener.active))
+	require.Equal(t, 1, testutil.CollectAndCount(listener.duration))
+}
+
+func TestPrometheusListenerRequiresRegistry(t *testing.T) {
+	_, err := NewPrometheusListener(PrometheusListenerOpts{})
+	require.Error(t, err)
+
+	registry := prometheus.NewRegistry()
+	_, err = NewPrometheusListener(PrometheusListenerOpts{Registry: registry})
+	require.NoError(t, err)
+	// metrics of two listeners can't be registered on the same registry
+	_, err = NewPrometheusListener(PrometheusListenerOpts{Registry: registry})
+	require.Error(t, err)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog"
@@ -14,7 +15,9 @@ type RangeGrafanaAnnotator struct {
 	client       *grafana.Client
 	dashboardUID string
 	chaosMap     map[string]int64 // Maps Chaos ID to Grafana Annotation ID
-	logger       zerolog.Logger
+	// mu guards chaosMap, listeners of different chaos objects are called concurrently
+	mu     *sync.Mutex
+	logger zerolog.Logger
 }
 
 func NewRangeGrafanaAnnotator(grafanaURL, grafanaToken, dashboardUID string, logger zerolog.Logger) *RangeGrafanaAnnotator {
@@ -22,10 +25,30 @@ func NewRangeGrafanaAnnotator(grafanaURL, grafanaToken, dashboardUID string, log
 		client:       grafana.NewGrafanaClient(grafanaURL, grafanaToken),
 		dashboardUID: dashboardUID,
 		chaosMap:     make(map[string]int64),
+		mu:           &sync.Mutex{},
 		logger:       logger,
 	}
 }
 
+func (l RangeGrafanaAnnotator) annotationID(name string) (int64, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	id, ok := l.chaosMap[name]
+	return id, ok
+}
+
+func (l RangeGrafanaAnnotator) setAnnotationID(name string, id int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.chaosMap[name] = id
+}
+
+func (l RangeGrafanaAnnotator) deleteAnnotationID(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.chaosMap, name)
+}
+
 func (l RangeGrafanaAnnotator) OnChaosCreated(chaos Chaos) {
 }
 
@@ -36,7 +59,7 @@ func (l RangeGrafanaAnnotator) OnChaosStarted(chaos Chaos) {
 	var sb strings.Builder
 	sb.WriteString("<body>")
 	sb.WriteString(fmt.Sprintf("<h4>%s Started</h4>", chaos.GetChaosTypeStr()))
-	sb.WriteString(fmt.Sprintf("<div>Name: %s</div>", chaos.Object.GetName()))
+	sb.WriteString(fmt.Sprintf("<div>Name: %s</div>", chaos.GetObject().GetName()))
 	if chaos.Description != "" {
 		sb.WriteString(fmt.Sprintf("<div>Description: %s</div>", chaos.Description))
 	}
@@ -76,14 +99,17 @@ func (l RangeGrafanaAnnotator) OnChaosStarted(chaos Chaos) {
 		l.logger.Warn().Msgf("could not annotate on Grafana: %s", err)
 	}
 
-	l.chaosMap[chaos.GetChaosName()] = res.ID
+	l.setAnnotationID(chaos.GetChaosName(), res.ID)
 }
 
 func (l RangeGrafanaAnnotator) OnChaosPaused(chaos Chaos) {
 }
 
+func (l RangeGrafanaAnnotator) OnChaosResumed(chaos Chaos) {
+}
+
 func (l RangeGrafanaAnnotator) OnChaosEnded(chaos Chaos) {
-	annotationID, exists := l.chaosMap[chaos.GetChaosName()]
+	annotationID, exists := l.annotationID(chaos.GetChaosName())
 	if !exists {
 		l.logger.Error().Msgf("No Grafana annotation ID found for Chaos: %s", chaos.GetChaosName())
 		return
@@ -95,7 +121,7 @@ func (l RangeGrafanaAnnotator) OnChaosEnded(chaos Chaos) {
 	var sb strings.Builder
 	sb.WriteString("<body>")
 	sb.WriteString(fmt.Sprintf("<h4>%s</h4>", chaos.GetChaosTypeStr()))
-	sb.WriteString(fmt.Sprintf("<div>Name: %s</div>", chaos.Object.GetName()))
+	sb.WriteString(fmt.Sprintf("<div>Name: %s</div>", chaos.GetObject().GetName()))
 	if chaos.Description != "" {
 		sb.WriteString(fmt.Sprintf("<div>Description: %s</div>", chaos.Description))
 	}
@@ -131,7 +157,7 @@ func (l RangeGrafanaAnnotator) OnChaosEnded(chaos Chaos) {
 	if err != nil {
 		l.logger.Error().Msgf("could not delete temporary start annotation: %s", err)
 	}
-	delete(l.chaosMap, chaos.GetChaosName())
+	l.deleteAnnotationID(chaos.GetChaosName())
 
 	// Create the final annotation (time range)
 	a := grafana.PostAnnotation{
@@ -144,7 +170,7 @@ func (l RangeGrafanaAnnotator) OnChaosEnded(chaos Chaos) {
 	if err != nil {
 		l.logger.Warn().Msgf("could not annotate on Grafana: %s", err)
 	}
-	l.chaosMap[chaos.GetChaosName()] = res.ID
+	l.setAnnotationID(chaos.GetChaosName(), res.ID)
 }
 
 func (l RangeGrafanaAnnotator) OnChaosStatusUnknown(chaos Chaos) {
@@ -184,11 +210,26 @@ func (l RangeGrafanaAnnotator) OnScheduleCreated(chaos Schedule) {
 		l.logger.Warn().Msgf("could not annotate on Grafana: %s", err)
 	}
 
-	l.chaosMap[chaos.Object.GetName()] = res.ID
+	l.setAnnotationID(chaos.Object.GetName(), res.ID)
+}
+
+func (l RangeGrafanaAnnotator) OnScheduleCreationFailed(chaos Schedule, reason error) {
+}
+
+func (l RangeGrafanaAnnotator) OnSchedulePaused(chaos Schedule) {
+}
+
+func (l RangeGrafanaAnnotator) OnScheduleResumed(chaos Schedule) {
+}
+
+func (l RangeGrafanaAnnotator) OnScheduledChaosStarted(chaos Schedule, spawned ScheduledChaos) {
+}
+
+func (l RangeGrafanaAnnotator) OnScheduledChaosEnded(chaos Schedule, spawned ScheduledChaos) {
 }
 
 func (l RangeGrafanaAnnotator) OnScheduleDeleted(chaos Schedule) {
-	annotationID, exists := l.chaosMap[chaos.Object.GetName()]
+	annotationID, exists := l.annotationID(chaos.Object.GetName())
 	if !exists {
 		l.logger.Error().Msgf("No Grafana annotation ID found for Chaos: %s", chaos.Object.GetName())
 		return
@@ -223,7 +264,7 @@ func (l RangeGrafanaAnnotator) OnScheduleDeleted(chaos Schedule) {
 	if err != nil {
 		l.logger.Error().Msgf("could not delete temporary start annotation: %s", err)
 	}
-	delete(l.chaosMap, chaos.Object.GetName())
+	l.deleteAnnotationID(chaos.Object.GetName())
 
 	// Create the final annotation (time range)
 	a := grafana.PostAnnotation{
@@ -236,5 +277,5 @@ func (l RangeGrafanaAnnotator) OnScheduleDeleted(chaos Schedule) {
 	if err != nil {
 		l.logger.Warn().Msgf("could not annotate on Grafana: %s", err)
 	}
-	l.chaosMap[chaos.Object.GetName()] = res.ID
+	l.setAnnotationID(chaos.Object.GetName(), res.ID)
 }
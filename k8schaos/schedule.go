@@ -2,22 +2,30 @@ package k8schaos
 
 import (
 	"context"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/chaos-mesh/chaos-mesh/api/v1alpha1"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type ScheduleStatus string
 
 const (
-	ScheduleStatusCreated ScheduleStatus = "created"
-	ScheduleStatusDeleted ScheduleStatus = "deleted"
-	ScheduleStatusUnknown ScheduleStatus = "unknown" // For any state that doesn't match the above
+	ScheduleStatusCreated        ScheduleStatus = "created"
+	ScheduleStatusCreationFailed ScheduleStatus = "creation_failed"
+	ScheduleStatusDeleted        ScheduleStatus = "deleted"
+	ScheduleStatusUnknown        ScheduleStatus = "unknown" // For any state that doesn't match the above
 )
 
+// Schedule is a Chaos Mesh schedule existing for Duration, its status is running since it's created
+// until it's deleted unless it's paused, read it with GetStatus.
+// Chaos objects spawned by the schedule are tracked from its status, listeners are notified when they start and end
 type Schedule struct {
 	Object        *v1alpha1.Schedule
 	Description   string
@@ -30,6 +38,21 @@ type Schedule struct {
 	startTime     time.Time
 	endTime       time.Time
 	logger        *zerolog.Logger
+	active        []ScheduledChaos
+	history       []ScheduledChaos
+	// mu guards Object, Status, listeners, cancelMonitor, times and spawned chaos
+	mu          *sync.RWMutex
+	dispatcher  *listenerDispatcher
+	creationErr error
+}
+
+// ScheduledChaos is a chaos object spawned by a schedule, it's active while the schedule lists it in its status
+type ScheduledChaos struct {
+	Kind      string
+	Name      string
+	Namespace string
+	StartTime time.Time
+	EndTime   time.Time // Zero while the chaos is active
 }
 
 type ScheduleOpts struct {
@@ -61,6 +84,8 @@ func NewSchedule(opts ScheduleOpts) (*Schedule, error) {
 		Client:      opts.Client,
 		listeners:   opts.Listeners,
 		logger:      opts.Logger,
+		mu:          &sync.RWMutex{},
+		dispatcher:  newListenerDispatcher(opts.Logger),
 	}, nil
 }
 
@@ -82,7 +107,7 @@ func (s *Schedule) Create(ctx context.Context) {
 			close(done) // Signal that the operation was canceled
 		case <-timer.C:
 			// Timer expired, check if deletion was not requested
-			if s.Status != StatusDeleted {
+			if s.GetStatus() != StatusDeleted {
 				s.createNow(ctx)
 			}
 			close(done) // Signal that the creation process is either done or skipped
@@ -90,33 +115,123 @@ func (s *Schedule) Create(ctx context.Context) {
 	}()
 }
 
+// Delete deletes the schedule, it does nothing if the schedule is already deleted.
+// Spawned chaos objects are deleted by Chaos Mesh with the schedule, so all active ones are ended
 func (s *Schedule) Delete(ctx context.Context) error {
-	if err := s.Client.Delete(ctx, s.Object); err != nil {
+	if s.GetStatus() == StatusDeleted {
+		return nil
+	}
+	if err := s.Client.Delete(ctx, s.getSchedule().DeepCopy()); err != nil {
 		return errors.Wrap(err, "failed to delete chaos object")
 	}
 
+	s.mu.Lock()
 	// Cancel the monitoring goroutine
 	if s.cancelMonitor != nil {
 		s.cancelMonitor()
 	}
-
-	s.endTime = time.Now()
+	deleted := s.Status != StatusDeleted
+	now := time.Now()
+	ended := s.endActiveLocked(nil, now)
+	s.endTime = now
 	s.Status = StatusDeleted
-	s.notifyListeners(string(ScheduleStatusDeleted))
+	s.mu.Unlock()
+	if deleted {
+		for _, child := range ended {
+			s.notifyChildListeners("chaos_ended", child)
+		}
+		s.notifyListeners(string(ScheduleStatusDeleted), nil)
+	}
 
 	return nil
 }
 
+// Pause sets Chaos Mesh pause annotation, the schedule doesn't spawn new chaos objects until it's resumed.
+// Listeners are notified when the paused schedule is observed
+func (s *Schedule) Pause(ctx context.Context) error {
+	if err := s.setPaused(ctx, true); err != nil {
+		return errors.Wrap(err, "could not update the annotation to set the schedule into pause state")
+	}
+	return nil
+}
+
+// Resume removes Chaos Mesh pause annotation, listeners are notified when the resumed schedule is observed
+func (s *Schedule) Resume(ctx context.Context) error {
+	if err := s.setPaused(ctx, false); err != nil {
+		return errors.Wrap(err, "could not remove the annotation to resume the schedule")
+	}
+	return nil
+}
+
+// setPaused toggles pause annotation on the latest version of the schedule, retrying if it was modified concurrently
+func (s *Schedule) setPaused(ctx context.Context, paused bool) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		schedule := &v1alpha1.Schedule{}
+		if err := s.Client.Get(ctx, client.ObjectKeyFromObject(s.getSchedule()), schedule); err != nil {
+			return errors.Wrap(err, "could not get the schedule")
+		}
+		annotations := schedule.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		if paused {
+			annotations[v1alpha1.PauseAnnotationKey] = strconv.FormatBool(true)
+		} else {
+			delete(annotations, v1alpha1.PauseAnnotationKey)
+		}
+		schedule.SetAnnotations(annotations)
+		if err := s.Client.Update(ctx, schedule); err != nil {
+			return err
+		}
+		s.setSchedule(schedule)
+		return nil
+	})
+}
+
 func (s *Schedule) AddListener(listener ChaosListener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.listeners = append(s.listeners, listener)
 }
 
+// RemoveListener removes the listener, events that are already dispatched are still delivered to it
+func (s *Schedule) RemoveListener(listener ChaosListener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = removeListener(s.listeners, listener)
+}
+
 func (s *Schedule) GetObject() client.Object {
+	return s.getSchedule()
+}
+
+func (s *Schedule) getSchedule() *v1alpha1.Schedule {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.Object
 }
 
+func (s *Schedule) setSchedule(schedule *v1alpha1.Schedule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Object = schedule
+}
+
+// GetStatus returns the current status of the schedule
+func (s *Schedule) GetStatus() ChaosStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Status
+}
+
+func (s *Schedule) creationError() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.creationErr
+}
+
 func (s *Schedule) GetChaosName() string {
-	return s.Object.GetName()
+	return s.getSchedule().GetName()
 }
 
 func (s *Schedule) GetChaosDescription() string {
@@ -124,7 +239,7 @@ func (s *Schedule) GetChaosDescription() string {
 }
 
 func (s *Schedule) GetChaosSpec() interface{} {
-	return s.Object.Spec.ScheduleItem
+	return s.getSchedule().Spec.ScheduleItem
 }
 
 func (s *Schedule) GetChaosDuration() (time.Duration, error) {
@@ -132,33 +247,65 @@ func (s *Schedule) GetChaosDuration() (time.Duration, error) {
 }
 
 func (s *Schedule) GetStartTime() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.startTime
 }
 
 func (s *Schedule) GetEndTime() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.endTime
 }
 
+// GetLastScheduleTime returns the time Chaos Mesh last spawned a chaos object, it's zero if nothing was spawned yet
+func (s *Schedule) GetLastScheduleTime() time.Time {
+	return s.getSchedule().Status.LastScheduleTime.Time
+}
+
+// GetActiveChaos returns chaos objects spawned by the schedule that are active
+func (s *Schedule) GetActiveChaos() []ScheduledChaos {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]ScheduledChaos(nil), s.active...)
+}
+
+// GetHistory returns chaos objects spawned by the schedule that ended, in the order they ended
+func (s *Schedule) GetHistory() []ScheduledChaos {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]ScheduledChaos(nil), s.history...)
+}
+
 func (s *Schedule) GetExpectedEndTime() (time.Time, error) {
 	duration, err := s.GetChaosDuration()
 	if err != nil {
 		return time.Time{}, err
 	}
-	return s.startTime.Add(duration), nil
+	return s.GetStartTime().Add(duration), nil
 }
 
 func (s *Schedule) createNow(ctx context.Context) {
-	if err := s.Client.Create(ctx, s.Object); err != nil {
-		Logger.Error().Err(err).Interface("chaos", s).Msg("failed to create chaos object")
+	schedule := s.getSchedule().DeepCopy()
+	if err := s.Client.Create(ctx, schedule); err != nil {
+		s.logger.Error().Err(err).Str("name", schedule.GetName()).Msg("failed to create chaos object")
+		s.mu.Lock()
+		s.Status = StatusCreationFailed
+		s.creationErr = err
+		s.mu.Unlock()
+		s.notifyListeners(string(ScheduleStatusCreationFailed), err)
 		return
 	}
-	s.startTime = time.Now()
-	s.Status = StatusCreated
-	s.notifyListeners(string(ScheduleStatusCreated))
 
 	// Create a cancellable context for monitorStatus
 	monitorCtx, cancel := context.WithCancel(ctx)
+	s.mu.Lock()
+	s.Object = schedule
+	s.startTime = time.Now()
+	s.Status = StatusRunning
 	s.cancelMonitor = cancel
+	s.mu.Unlock()
+	s.notifyListeners(string(ScheduleStatusCreated), nil)
 	go s.monitorStatus(monitorCtx)
 
 	// Start a deletion timer to delete the chaos object after the specified duration
@@ -187,18 +334,94 @@ func (s *Schedule) createNow(ctx context.Context) {
 	}()
 }
 
-func (s *Schedule) notifyListeners(event string) {
-	for _, listener := range s.listeners {
+// snapshot returns a copy of the schedule state for listeners, it doesn't change when the schedule is updated
+func (s *Schedule) snapshot() (Schedule, []ChaosListener) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	schedule := Schedule{
+		Object:      s.Object.DeepCopy(),
+		Description: s.Description,
+		DelayCreate: s.DelayCreate,
+		Duration:    s.Duration,
+		Status:      s.Status,
+		Client:      s.Client,
+		startTime:   s.startTime,
+		endTime:     s.endTime,
+		active:      append([]ScheduledChaos(nil), s.active...),
+		history:     append([]ScheduledChaos(nil), s.history...),
+		logger:      s.logger,
+		mu:          &sync.RWMutex{},
+	}
+	return schedule, append([]ChaosListener(nil), s.listeners...)
+}
+
+// notifyListeners dispatches the event with a snapshot of the schedule to listeners registered at the moment
+func (s *Schedule) notifyListeners(event string, err error) {
+	schedule, listeners := s.snapshot()
+	s.dispatcher.dispatch(event, listeners, func(listener ChaosListener) {
 		switch event {
 		case string(ScheduleStatusCreated):
-			listener.OnScheduleCreated(*s)
+			listener.OnScheduleCreated(schedule)
+		case string(ScheduleStatusCreationFailed):
+			listener.OnScheduleCreationFailed(schedule, err)
+		case "paused":
+			listener.OnSchedulePaused(schedule)
+		case "resumed":
+			listener.OnScheduleResumed(schedule)
 		case string(ScheduleStatusDeleted):
-			listener.OnScheduleDeleted(*s)
+			listener.OnScheduleDeleted(schedule)
 		}
-	}
+	})
 }
 
+// notifyChildListeners dispatches the event of a spawned chaos object
+func (s *Schedule) notifyChildListeners(event string, child ScheduledChaos) {
+	schedule, listeners := s.snapshot()
+	s.dispatcher.dispatch(event, listeners, func(listener ChaosListener) {
+		switch event {
+		case "chaos_started":
+			listener.OnScheduledChaosStarted(schedule, child)
+		case "chaos_ended":
+			listener.OnScheduledChaosEnded(schedule, child)
+		}
+	})
+}
+
+// monitorStatus keeps the Schedule object up to date with a shared watch, clients without watch support are polled every 10 seconds
 func (s *Schedule) monitorStatus(ctx context.Context) {
+	wc, ok := s.Client.(client.WithWatch)
+	if !ok {
+		s.pollStatus(ctx)
+		return
+	}
+	updates, unsubscribe, err := subscribeObject(wc, s.getSchedule(), s.logger)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("failed to watch Schedule object")
+		s.pollStatus(ctx)
+		return
+	}
+	defer unsubscribe()
+
+	// the object could change before the watch was started
+	if err := s.updateSchedule(ctx); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to get Schedule object")
+	} else {
+		s.handleStatus()
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case obj := <-updates:
+			if schedule, ok := obj.(*v1alpha1.Schedule); ok {
+				s.setSchedule(schedule)
+				s.handleStatus()
+			}
+		}
+	}
+}
+
+func (s *Schedule) pollStatus(ctx context.Context) {
 	ticker := time.NewTicker(10 * time.Second)
 	defer ticker.Stop()
 
@@ -209,17 +432,101 @@ func (s *Schedule) monitorStatus(ctx context.Context) {
 			return
 		case <-ticker.C:
 			// Fetch the latest state of the Schedule object
-			var schedule v1alpha1.Schedule
-			if err := s.Client.Get(ctx, client.ObjectKey{
-				Namespace: s.Object.GetNamespace(),
-				Name:      s.Object.GetName(),
-			}, &schedule); err != nil {
-				Logger.Error().Err(err).Msg("Failed to get Schedule object")
+			if err := s.updateSchedule(ctx); err != nil {
+				s.logger.Error().Err(err).Msg("Failed to get Schedule object")
 				continue
 			}
+			s.handleStatus()
+		}
+	}
+}
+
+func (s *Schedule) updateSchedule(ctx context.Context) error {
+	var schedule v1alpha1.Schedule
+	if err := s.Client.Get(ctx, client.ObjectKeyFromObject(s.getSchedule()), &schedule); err != nil {
+		return err
+	}
+	s.setSchedule(&schedule)
+	return nil
+}
+
+// handleStatus derives schedule status from its pause annotation and tracks spawned chaos objects
+// from its active list, listeners are notified about every change
+func (s *Schedule) handleStatus() {
+	s.mu.Lock()
+	if s.Status != StatusRunning && s.Status != StatusPaused {
+		s.mu.Unlock()
+		return
+	}
+	schedule := s.Object
+	previousStatus := s.Status
+	if schedule.IsPaused() {
+		s.Status = StatusPaused
+	} else {
+		s.Status = StatusRunning
+	}
+	currentStatus := s.Status
+
+	now := time.Now()
+	var started []ScheduledChaos
+	for _, ref := range schedule.Status.Active {
+		child := ScheduledChaos{Kind: ref.Kind, Name: ref.Name, Namespace: ref.Namespace, StartTime: now}
+		if child.Kind == "" {
+			child.Kind = string(schedule.Spec.Type)
+		}
+		if child.Namespace == "" {
+			child.Namespace = schedule.Namespace
+		}
+		if s.activeIndexLocked(child) < 0 {
+			s.active = append(s.active, child)
+			started = append(started, child)
+		}
+	}
+	ended := s.endActiveLocked(schedule.Status.Active, now)
+	s.mu.Unlock()
 
-			// Log or process the schedule's current status
-			// Loggerog.Info().Interface("status", schedule.Status).Msg("Current Schedule Status")
+	for _, child := range ended {
+		s.notifyChildListeners("chaos_ended", child)
+	}
+	for _, child := range started {
+		s.notifyChildListeners("chaos_started", child)
+	}
+	switch {
+	case previousStatus == StatusRunning && currentStatus == StatusPaused:
+		s.notifyListeners("paused", nil)
+	case previousStatus == StatusPaused && currentStatus == StatusRunning:
+		s.notifyListeners("resumed", nil)
+	}
+}
+
+func (s *Schedule) activeIndexLocked(child ScheduledChaos) int {
+	for i, a := range s.active {
+		if a.Kind == child.Kind && a.Namespace == child.Namespace && a.Name == child.Name {
+			return i
+		}
+	}
+	return -1
+}
+
+// endActiveLocked moves active chaos objects missing in refs to history and returns them
+func (s *Schedule) endActiveLocked(refs []corev1.ObjectReference, now time.Time) []ScheduledChaos {
+	var active, ended []ScheduledChaos
+	for _, child := range s.active {
+		found := false
+		for _, ref := range refs {
+			if ref.Name == child.Name && (ref.Namespace == "" || ref.Namespace == child.Namespace) {
+				found = true
+				break
+			}
+		}
+		if found {
+			active = append(active, child)
+			continue
 		}
+		child.EndTime = now
+		ended = append(ended, child)
 	}
+	s.active = active
+	s.history = append(s.history, ended...)
+	return ended
 }
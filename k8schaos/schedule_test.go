@@ -0,0 +1,80 @@
+package k8schaos
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/chaos-mesh/chaos-mesh/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// setActive sets spawned chaos objects of the schedule the way Chaos Mesh controller does
+func setActive(t *testing.T, c client.Client, name string, lastScheduleTime time.Time, active ...string) {
+	t.Helper()
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		obj := &v1alpha1.Schedule{}
+		if err := c.Get(context.Background(), client.ObjectKey{Namespace: testNamespace, Name: name}, obj); err != nil {
+			return err
+		}
+		obj.Status.Active = nil
+		for _, a := range active {
+			obj.Status.Active = append(obj.Status.Active, corev1.ObjectReference{Kind: "PodChaos", Namespace: testNamespace, Name: a})
+		}
+		obj.Status.LastScheduleTime = metav1.NewTime(lastScheduleTime)
+		return c.Update(context.Background(), obj)
+	})
+	require.NoError(t, err)
+}
+
+func TestScheduleLifecycle(t *testing.T) {
+	c := newFakeClient(t)
+	rec := newEventRecorder()
+	schedule := newTestSchedule(t, c, "pod-failure-schedule", rec)
+	ctx, cancel := context.WithTimeout(context.Background(), testEventTimeout)
+	defer cancel()
+
+	schedule.Create(ctx)
+	rec.requireEvent(t, "schedule_created")
+	require.Equal(t, StatusRunning, schedule.GetStatus())
+
+	firstScheduled := time.Now().Truncate(time.Second)
+	setActive(t, c, "pod-failure-schedule", firstScheduled, "pod-failure-1")
+	rec.requireEvent(t, "scheduled_chaos_started")
+	require.Equal(t, firstScheduled.UTC(), schedule.GetLastScheduleTime().UTC())
+	active := schedule.GetActiveChaos()
+	require.Len(t, active, 1)
+	require.Equal(t, ScheduledChaos{Kind: "PodChaos", Name: "pod-failure-1", Namespace: testNamespace, StartTime: active[0].StartTime}, active[0])
+
+	secondScheduled := firstScheduled.Add(time.Minute)
+	setActive(t, c, "pod-failure-schedule", secondScheduled, "pod-failure-2")
+	rec.requireEvent(t, "scheduled_chaos_ended")
+	rec.requireEvent(t, "scheduled_chaos_started")
+	history := schedule.GetHistory()
+	require.Len(t, history, 1)
+	require.Equal(t, "pod-failure-1", history[0].Name)
+	require.False(t, history[0].EndTime.IsZero())
+	require.Equal(t, secondScheduled.UTC(), schedule.GetLastScheduleTime().UTC())
+
+	require.NoError(t, schedule.Pause(ctx))
+	rec.requireEvent(t, "schedule_paused")
+	require.Equal(t, StatusPaused, schedule.GetStatus())
+	obj := &v1alpha1.Schedule{}
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: "pod-failure-schedule"}, obj))
+	require.Equal(t, "true", obj.GetAnnotations()[v1alpha1.PauseAnnotationKey])
+
+	require.NoError(t, schedule.Resume(ctx))
+	rec.requireEvent(t, "schedule_resumed")
+	require.Equal(t, StatusRunning, schedule.GetStatus())
+
+	// spawned chaos objects are deleted with the schedule
+	require.NoError(t, schedule.Delete(ctx))
+	rec.requireEvent(t, "scheduled_chaos_ended")
+	rec.requireEvent(t, "schedule_deleted")
+	require.Empty(t, schedule.GetActiveChaos())
+	require.Len(t, schedule.GetHistory(), 2)
+}
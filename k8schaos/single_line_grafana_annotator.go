@@ -37,7 +37,7 @@ func (l SingleLineGrafanaAnnotator) OnChaosStarted(chaos Chaos) {
 	var sb strings.Builder
 	sb.WriteString("<body>")
 	sb.WriteString(fmt.Sprintf("<h4>%s Started</h4>", chaos.GetChaosTypeStr()))
-	sb.WriteString(fmt.Sprintf("<div>Name: %s</div>", chaos.Object.GetName()))
+	sb.WriteString(fmt.Sprintf("<div>Name: %s</div>", chaos.GetObject().GetName()))
 	if chaos.Description != "" {
 		sb.WriteString(fmt.Sprintf("<div>Description: %s</div>", chaos.Description))
 	}
@@ -81,6 +81,9 @@ func (l SingleLineGrafanaAnnotator) OnChaosStarted(chaos Chaos) {
 func (l SingleLineGrafanaAnnotator) OnChaosPaused(chaos Chaos) {
 }
 
+func (l SingleLineGrafanaAnnotator) OnChaosResumed(chaos Chaos) {
+}
+
 func (l SingleLineGrafanaAnnotator) OnChaosEnded(chaos Chaos) {
 	experiment, _ := chaos.GetExperimentStatus()
 	duration, _ := chaos.GetChaosDuration()
@@ -88,7 +91,7 @@ func (l SingleLineGrafanaAnnotator) OnChaosEnded(chaos Chaos) {
 	var sb strings.Builder
 	sb.WriteString("<body>")
 	sb.WriteString(fmt.Sprintf("<h4>%s Ended</h4>", chaos.GetChaosTypeStr()))
-	sb.WriteString(fmt.Sprintf("<div>Name: %s</div>", chaos.Object.GetName()))
+	sb.WriteString(fmt.Sprintf("<div>Name: %s</div>", chaos.GetObject().GetName()))
 	if chaos.Description != "" {
 		sb.WriteString(fmt.Sprintf("<div>Description: %s</div>", chaos.Description))
 	}
@@ -168,6 +171,21 @@ func (l SingleLineGrafanaAnnotator) OnScheduleCreated(s Schedule) {
 	}
 }
 
+func (l SingleLineGrafanaAnnotator) OnScheduleCreationFailed(s Schedule, reason error) {
+}
+
+func (l SingleLineGrafanaAnnotator) OnSchedulePaused(s Schedule) {
+}
+
+func (l SingleLineGrafanaAnnotator) OnScheduleResumed(s Schedule) {
+}
+
+func (l SingleLineGrafanaAnnotator) OnScheduledChaosStarted(s Schedule, spawned ScheduledChaos) {
+}
+
+func (l SingleLineGrafanaAnnotator) OnScheduledChaosEnded(s Schedule, spawned ScheduledChaos) {
+}
+
 func (l SingleLineGrafanaAnnotator) OnScheduleDeleted(s Schedule) {
 	var sb strings.Builder
 	sb.WriteString("<body>")
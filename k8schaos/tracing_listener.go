@@ -0,0 +1,253 @@
+package k8schaos
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/chaos-mesh/chaos-mesh/api/v1alpha1"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// tracerName is the instrumentation name of chaos spans
+const tracerName = "github.com/smartcontractkit/havoc/k8schaos"
+
+// TracingListener is a ChaosListener representing every chaos experiment as an OpenTelemetry span
+// from its creation until it's ended, and every schedule as a span until it's deleted.
+// Pause, resume and unknown status are recorded as span events
+type TracingListener struct {
+	tracer trace.Tracer
+	mu     sync.Mutex
+	spans  map[spanKey]trace.Span
+}
+
+func NewTracingListener(provider trace.TracerProvider) (*TracingListener, error) {
+	if provider == nil {
+		return nil, errors.New("tracer provider is required")
+	}
+	return &TracingListener{
+		tracer: provider.Tracer(tracerName),
+		spans:  make(map[spanKey]trace.Span),
+	}, nil
+}
+
+// spanKey identifies the object of a span, objects of different kinds can have the same name
+// and an object created again with the same name has a new UID
+type spanKey struct {
+	kind      string
+	namespace string
+	name      string
+	uid       types.UID
+}
+
+func newSpanKey(kind string, obj client.Object) spanKey {
+	return spanKey{kind: kind, namespace: obj.GetNamespace(), name: obj.GetName(), uid: obj.GetUID()}
+}
+
+// sameObjectName checks if keys are of the same kind and name, regardless of UID
+func (k spanKey) sameObjectName(other spanKey) bool {
+	return k.kind == other.kind && k.namespace == other.namespace && k.name == other.name
+}
+
+func chaosSpanKey(chaos Chaos) spanKey {
+	return newSpanKey(chaos.GetChaosTypeStr(), chaos.GetObject())
+}
+
+func scheduleSpanKey(s Schedule) spanKey {
+	return newSpanKey("Schedule", s.GetObject())
+}
+
+// chaosAttributes returns attributes of the chaos object, selector and mode are taken from its main selector
+func chaosAttributes(chaos Chaos) []attribute.KeyValue {
+	obj := chaos.GetObject()
+	attrs := []attribute.KeyValue{
+		attribute.String("chaos.kind", chaos.GetChaosTypeStr()),
+		attribute.String("chaos.name", obj.GetName()),
+		attribute.String("chaos.namespace", obj.GetNamespace()),
+		attribute.String("chaos.description", chaos.GetChaosDescription()),
+	}
+	if duration, err := chaos.GetChaosDuration(); err == nil {
+		attrs = append(attrs, attribute.String("chaos.duration", duration.String()))
+	}
+	withSelector, ok := obj.(v1alpha1.InnerObjectWithSelector)
+	if !ok {
+		return attrs
+	}
+	var selector interface{}
+	switch s := withSelector.GetSelectorSpecs()["."].(type) {
+	case *v1alpha1.PodSelector:
+		selector = s.Selector
+		attrs = append(attrs, attribute.String("chaos.mode", string(s.Mode)))
+	case *v1alpha1.ContainerSelector:
+		selector = s.Selector
+		attrs = append(attrs, attribute.String("chaos.mode", string(s.Mode)))
+	case nil:
+	default:
+		selector = s
+	}
+	if selector != nil {
+		if b, err := json.Marshal(selector); err == nil {
+			attrs = append(attrs, attribute.String("chaos.selector", string(b)))
+		}
+	}
+	return attrs
+}
+
+// recordsAttribute returns ids and phases of targets the faults are injected into
+func recordsAttribute(chaos Chaos) attribute.KeyValue {
+	experiment, _ := chaos.GetExperimentStatus()
+	records := make([]string, 0, len(experiment.Records))
+	for _, r := range experiment.Records {
+		records = append(records, r.Id+": "+string(r.Phase))
+	}
+	return attribute.StringSlice("chaos.records", records)
+}
+
+func (l *TracingListener) startSpan(key spanKey, name string, start time.Time, attrs []attribute.KeyValue) {
+	_, span := l.tracer.Start(context.Background(), name, trace.WithTimestamp(start), trace.WithAttributes(attrs...))
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// the object was created again with the same name, its previous span can't be ended anymore
+	for k, previous := range l.spans {
+		if k.sameObjectName(key) {
+			previous.End()
+			delete(l.spans, k)
+		}
+	}
+	l.spans[key] = span
+}
+
+func (l *TracingListener) span(key spanKey) (trace.Span, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	span, ok := l.spans[key]
+	return span, ok
+}
+
+func (l *TracingListener) endSpan(key spanKey) (trace.Span, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	span, ok := l.spans[key]
+	delete(l.spans, key)
+	return span, ok
+}
+
+func (l *TracingListener) addEvent(key spanKey, name string, attrs ...attribute.KeyValue) {
+	if span, ok := l.span(key); ok {
+		span.AddEvent(name, trace.WithAttributes(attrs...))
+	}
+}
+
+func (l *TracingListener) OnChaosCreated(chaos Chaos) {
+	// the event is delivered asynchronously, the creation time of the object is used if API server set it
+	start := chaos.GetObject().GetCreationTimestamp().Time
+	if start.IsZero() {
+		start = time.Now()
+	}
+	l.startSpan(chaosSpanKey(chaos), chaos.GetChaosTypeStr(), start, chaosAttributes(chaos))
+}
+
+func (l *TracingListener) OnChaosCreationFailed(chaos Chaos, reason error) {
+	_, span := l.tracer.Start(context.Background(), chaos.GetChaosTypeStr(), trace.WithAttributes(chaosAttributes(chaos)...))
+	span.RecordError(reason)
+	span.SetStatus(codes.Error, "chaos creation failed")
+	span.End()
+}
+
+func (l *TracingListener) OnChaosStarted(chaos Chaos) {
+	if span, ok := l.span(chaosSpanKey(chaos)); ok {
+		span.SetAttributes(recordsAttribute(chaos))
+		span.AddEvent("started", trace.WithTimestamp(chaos.GetStartTime()))
+	}
+}
+
+func (l *TracingListener) OnChaosPaused(chaos Chaos) {
+	l.addEvent(chaosSpanKey(chaos), "paused")
+}
+
+func (l *TracingListener) OnChaosResumed(chaos Chaos) {
+	l.addEvent(chaosSpanKey(chaos), "resumed")
+}
+
+func (l *TracingListener) OnChaosEnded(chaos Chaos) {
+	span, ok := l.endSpan(chaosSpanKey(chaos))
+	if !ok {
+		return
+	}
+	span.SetAttributes(recordsAttribute(chaos))
+	end := chaos.GetEndTime()
+	if end.IsZero() {
+		end = time.Now()
+	}
+	span.End(trace.WithTimestamp(end))
+}
+
+func (l *TracingListener) OnChaosStatusUnknown(chaos Chaos) {
+	status, err := chaos.GetChaosStatus()
+	if err != nil || status == nil {
+		l.addEvent(chaosSpanKey(chaos), "status unknown")
+		return
+	}
+	conditions := make([]string, 0, len(status.Conditions))
+	for _, c := range status.Conditions {
+		conditions = append(conditions, string(c.Type)+"="+string(c.Status))
+	}
+	l.addEvent(chaosSpanKey(chaos), "status unknown",
+		attribute.StringSlice("chaos.conditions", conditions),
+		attribute.String("chaos.desired_phase", string(status.Experiment.DesiredPhase)))
+}
+
+func (l *TracingListener) OnScheduleCreated(s Schedule) {
+	obj := s.GetObject()
+	l.startSpan(scheduleSpanKey(s), "Schedule", s.GetStartTime(), []attribute.KeyValue{
+		attribute.String("chaos.kind", "Schedule"),
+		attribute.String("chaos.name", obj.GetName()),
+		attribute.String("chaos.namespace", obj.GetNamespace()),
+		attribute.String("chaos.description", s.GetChaosDescription()),
+		attribute.String("chaos.schedule", s.Object.Spec.Schedule),
+		attribute.String("chaos.schedule_type", string(s.Object.Spec.Type)),
+	})
+}
+
+func (l *TracingListener) OnScheduleCreationFailed(s Schedule, reason error) {
+	_, span := l.tracer.Start(context.Background(), "Schedule", trace.WithAttributes(
+		attribute.String("chaos.kind", "Schedule"),
+		attribute.String("chaos.name", s.GetObject().GetName()),
+		attribute.String("chaos.namespace", s.GetObject().GetNamespace()),
+	))
+	span.RecordError(reason)
+	span.SetStatus(codes.Error, "schedule creation failed")
+	span.End()
+}
+
+func (l *TracingListener) OnSchedulePaused(s Schedule) {
+	l.addEvent(scheduleSpanKey(s), "paused")
+}
+
+func (l *TracingListener) OnScheduleResumed(s Schedule) {
+	l.addEvent(scheduleSpanKey(s), "resumed")
+}
+
+func (l *TracingListener) OnScheduledChaosStarted(s Schedule, spawned ScheduledChaos) {
+	l.addEvent(scheduleSpanKey(s), "chaos started",
+		attribute.String("chaos.kind", spawned.Kind),
+		attribute.String("chaos.name", spawned.Name))
+}
+
+func (l *TracingListener) OnScheduledChaosEnded(s Schedule, spawned ScheduledChaos) {
+	l.addEvent(scheduleSpanKey(s), "chaos ended",
+		attribute.String("chaos.kind", spawned.Kind),
+		attribute.String("chaos.name", spawned.Name))
+}
+
+func (l *TracingListener) OnScheduleDeleted(s Schedule) {
+	if span, ok := l.endSpan(scheduleSpanKey(s)); ok {
+		span.End(trace.WithTimestamp(s.GetEndTime()))
+	}
+}
@@ -0,0 +1,129 @@
+package k8schaos
+
+import (
+	"context"
+	"testing"
+
+	"github.com/chaos-mesh/chaos-mesh/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func spanAttributes(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func spanEvents(span tracetest.SpanStub) []string {
+	events := make([]string, 0, len(span.Events))
+	for _, e := range span.Events {
+		events = append(events, e.Name)
+	}
+	return events
+}
+
+func TestTracingListener(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	listener, err := NewTracingListener(provider)
+	require.NoError(t, err)
+	c := newFakeClient(t)
+	rec := newEventRecorder()
+	chaos := newTestPodChaos(t, c, "pod-failure", listener, rec)
+
+	chaos.Create(context.Background())
+	rec.requireEvent(t, "created")
+	injectWhenCreated(t, c, "pod-failure")
+	rec.requireEvent(t, "started")
+	require.NoError(t, chaos.Pause(context.Background()))
+	setConditions(t, c, "pod-failure", map[v1alpha1.ChaosConditionType]corev1.ConditionStatus{
+		v1alpha1.ConditionAllRecovered: corev1.ConditionTrue,
+		v1alpha1.ConditionPaused:       corev1.ConditionTrue,
+	})
+	rec.requireEvent(t, "paused")
+	// nothing is selected, chaos status is unknown
+	setConditions(t, c, "pod-failure", nil)
+	rec.requireEvent(t, "unknown")
+	require.Empty(t, exporter.GetSpans(), "span must not be ended before chaos")
+
+	require.NoError(t, chaos.Delete(context.Background()))
+	rec.requireEvent(t, "ended")
+	chaos.dispatcher.wait()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	require.Equal(t, "PodChaos", span.Name)
+	attrs := spanAttributes(span)
+	require.Equal(t, "PodChaos", attrs["chaos.kind"].AsString())
+	require.Equal(t, "pod-failure", attrs["chaos.name"].AsString())
+	require.Equal(t, string(v1alpha1.AllMode), attrs["chaos.mode"].AsString())
+	require.Contains(t, attrs, attribute.Key("chaos.selector"))
+	require.Contains(t, attrs, attribute.Key("chaos.records"))
+	require.Equal(t, []string{"started", "paused", "status unknown"}, spanEvents(span))
+	require.False(t, span.EndTime.Before(span.StartTime))
+}
+
+func TestTracingListenerSpanKeys(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	listener, err := NewTracingListener(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	require.NoError(t, err)
+	c := newFakeClient(t)
+	newChaos := func(obj client.Object, uid types.UID) Chaos {
+		obj.SetName("same-name")
+		obj.SetNamespace(testNamespace)
+		obj.SetUID(uid)
+		chaos, err := NewChaos(ChaosOpts{Object: obj, Client: c, Logger: &Logger})
+		require.NoError(t, err)
+		return *chaos
+	}
+	pod := newChaos(&v1alpha1.PodChaos{}, "pod-1")
+	network := newChaos(&v1alpha1.NetworkChaos{}, "network-1")
+
+	// chaos of different kinds with the same name have their own spans
+	listener.OnChaosCreated(pod)
+	listener.OnChaosCreated(network)
+	listener.OnChaosEnded(network)
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	require.Equal(t, "NetworkChaos", spans[0].Name)
+
+	// the old span of chaos created again with the same name is ended, the new one is not ended by events of the old one
+	recreated := newChaos(&v1alpha1.PodChaos{}, "pod-2")
+	listener.OnChaosCreated(recreated)
+	require.Len(t, exporter.GetSpans(), 2)
+	listener.OnChaosEnded(pod)
+	require.Len(t, exporter.GetSpans(), 2)
+	listener.OnChaosEnded(recreated)
+	require.Len(t, exporter.GetSpans(), 3)
+}
+
+func TestTracingListenerCreationFailure(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	listener, err := NewTracingListener(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	require.NoError(t, err)
+	c := newFakeClient(t)
+	rec := newEventRecorder()
+	chaos := newTestPodChaos(t, c, "pod-failure", listener, rec)
+	require.NoError(t, c.Create(context.Background(), chaos.GetObject().DeepCopyObject().(*v1alpha1.PodChaos)))
+
+	chaos.Create(context.Background())
+	rec.requireEvent(t, "creation_failed")
+	chaos.dispatcher.wait()
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	require.Equal(t, codes.Error, spans[0].Status.Code)
+	require.Equal(t, []string{"exception"}, spanEvents(spans[0]))
+
+	_, err = NewTracingListener(nil)
+	require.Error(t, err)
+}
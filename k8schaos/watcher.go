@@ -0,0 +1,175 @@
+package k8schaos
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// rewatchInterval is a delay before the watch is started again when API server closes it
+const rewatchInterval = time.Second
+
+// objectWatcher is a watch of one kind in one namespace shared by all chaos objects of that kind,
+// it dispatches every change of an object to subscribers of the object name
+type objectWatcher struct {
+	key         watcherKey
+	client      client.WithWatch
+	list        client.ObjectList
+	logger      *zerolog.Logger
+	cancel      context.CancelFunc
+	mu          sync.Mutex
+	subscribers map[string][]chan client.Object
+}
+
+type watcherKey struct {
+	// client is identity of the client the watch is started with, see clientKey
+	client    interface{}
+	kind      string
+	namespace string
+}
+
+var (
+	watchersMu sync.Mutex
+	watchers   = make(map[watcherKey]*objectWatcher)
+)
+
+// clientKey returns a comparable identity of the client: clients are pointers, so watches of one client are shared,
+// clients of other types could be not comparable, every subscription of such client gets its own watch
+func clientKey(c client.WithWatch) interface{} {
+	if reflect.ValueOf(c).Kind() == reflect.Pointer {
+		return c
+	}
+	return new(byte)
+}
+
+// subscribeObject returns a channel receiving the latest version of obj every time it changes,
+// the watch of its kind is started for the first subscriber and stopped when the last one unsubscribes,
+// watch errors are logged with the logger of the first subscriber
+func subscribeObject(c client.WithWatch, obj client.Object, logger *zerolog.Logger) (<-chan client.Object, func(), error) {
+	kind, chaosKind, err := lookupKind(obj)
+	if err != nil {
+		return nil, nil, err
+	}
+	list := chaosKind.SpawnList()
+	key := watcherKey{client: clientKey(c), kind: kind, namespace: obj.GetNamespace()}
+
+	watchersMu.Lock()
+	defer watchersMu.Unlock()
+	w, ok := watchers[key]
+	if !ok {
+		ctx, cancel := context.WithCancel(context.Background())
+		// the first watch is started before subscribers read the object, so no change is missed between the read and the watch
+		wi, err := c.Watch(ctx, list, client.InNamespace(key.namespace))
+		if err != nil {
+			cancel()
+			return nil, nil, errors.Wrap(err, "failed to watch chaos objects")
+		}
+		w = &objectWatcher{
+			key:         key,
+			client:      c,
+			list:        list,
+			logger:      logger,
+			cancel:      cancel,
+			subscribers: make(map[string][]chan client.Object),
+		}
+		watchers[key] = w
+		go w.run(ctx, wi)
+	}
+	// the latest version replaces the one the subscriber hasn't received yet
+	ch := make(chan client.Object, 1)
+	w.mu.Lock()
+	w.subscribers[obj.GetName()] = append(w.subscribers[obj.GetName()], ch)
+	w.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			w.unsubscribe(obj.GetName(), ch)
+		})
+	}
+	return ch, unsubscribe, nil
+}
+
+func (w *objectWatcher) unsubscribe(name string, ch chan client.Object) {
+	watchersMu.Lock()
+	defer watchersMu.Unlock()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	subs := w.subscribers[name]
+	for i, s := range subs {
+		if s == ch {
+			subs = append(subs[:i], subs[i+1:]...)
+			break
+		}
+	}
+	if len(subs) == 0 {
+		delete(w.subscribers, name)
+	} else {
+		w.subscribers[name] = subs
+	}
+	if len(w.subscribers) == 0 {
+		w.cancel()
+		delete(watchers, w.key)
+	}
+}
+
+// run dispatches events until the watcher is stopped, the watch is started again if API server closes it
+func (w *objectWatcher) run(ctx context.Context, wi watch.Interface) {
+	for {
+		w.dispatchEvents(ctx, wi)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(rewatchInterval):
+		}
+		var err error
+		wi, err = w.client.Watch(ctx, w.list, client.InNamespace(w.key.namespace))
+		for err != nil {
+			w.logger.Error().Err(err).Str("kind", w.key.kind).Str("namespace", w.key.namespace).Msg("failed to watch chaos objects")
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(rewatchInterval):
+			}
+			wi, err = w.client.Watch(ctx, w.list, client.InNamespace(w.key.namespace))
+		}
+	}
+}
+
+func (w *objectWatcher) dispatchEvents(ctx context.Context, wi watch.Interface) {
+	defer wi.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-wi.ResultChan():
+			if !ok {
+				return
+			}
+			if event.Type != watch.Added && event.Type != watch.Modified {
+				continue
+			}
+			if obj, ok := event.Object.(client.Object); ok {
+				w.dispatch(obj)
+			}
+		}
+	}
+}
+
+func (w *objectWatcher) dispatch(obj client.Object) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, ch := range w.subscribers[obj.GetName()] {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- obj.DeepCopyObject().(client.Object)
+	}
+}
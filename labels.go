@@ -0,0 +1,134 @@
+package havoc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	ErrUnknownLabelOperator = "unknown label expression operator, only In, NotIn, Exists and DoesNotExist are supported"
+)
+
+// Label expression operators, the same as in Kubernetes set-based selectors
+const (
+	LabelOperatorIn           = "In"
+	LabelOperatorNotIn        = "NotIn"
+	LabelOperatorExists       = "Exists"
+	LabelOperatorDoesNotExist = "DoesNotExist"
+)
+
+// LabelExpression is a set-based label requirement, rendered as ChaosMesh expressionSelectors
+type LabelExpression struct {
+	Key      string   `toml:"key" yaml:"key"`
+	Operator string   `toml:"operator" yaml:"operator"`
+	Values   []string `toml:"values" yaml:"values"`
+}
+
+// Validate checks expression operator and values
+func (e *LabelExpression) Validate() error {
+	switch e.Operator {
+	case LabelOperatorIn, LabelOperatorNotIn:
+		if len(e.Values) == 0 {
+			return errors.Wrap(errors.New(ErrFormat), fmt.Sprintf("label expression %s %s must have values", e.Key, e.Operator))
+		}
+	case LabelOperatorExists, LabelOperatorDoesNotExist:
+		if len(e.Values) != 0 {
+			return errors.Wrap(errors.New(ErrFormat), fmt.Sprintf("label expression %s %s must not have values", e.Key, e.Operator))
+		}
+	default:
+		return errors.Wrap(errors.New(ErrUnknownLabelOperator), e.Operator)
+	}
+	return nil
+}
+
+// Matches checks if labels satisfy expression
+func (e *LabelExpression) Matches(labels map[string]string) (bool, error) {
+	v, ok := labels[e.Key]
+	switch e.Operator {
+	case LabelOperatorIn:
+		return ok && sliceContains(v, e.Values), nil
+	case LabelOperatorNotIn:
+		return !ok || !sliceContains(v, e.Values), nil
+	case LabelOperatorExists:
+		return ok, nil
+	case LabelOperatorDoesNotExist:
+		return !ok, nil
+	default:
+		return false, errors.Wrap(errors.New(ErrUnknownLabelOperator), e.Operator)
+	}
+}
+
+// String transforms expression to ChaosMesh expressionSelectors item in YAML flow format
+func (e *LabelExpression) String() string {
+	if len(e.Values) == 0 {
+		return fmt.Sprintf("{key: %s, operator: %s}", flowQuote(e.Key), e.Operator)
+	}
+	values := make([]string, 0)
+	for _, v := range e.Values {
+		values = append(values, flowQuote(v))
+	}
+	return fmt.Sprintf("{key: %s, operator: %s, values: [%s]}", flowQuote(e.Key), e.Operator, strings.Join(values, ", "))
+}
+
+// flowQuote single quotes a YAML flow scalar, quotes inside are escaped by doubling them
+func flowQuote(s string) string {
+	return fmt.Sprintf("'%s'", strings.ReplaceAll(s, "'", "''"))
+}
+
+// matchesExpressions checks if labels satisfy all expressions
+func matchesExpressions(exprs []*LabelExpression, labels map[string]string) (bool, error) {
+	for _, e := range exprs {
+		ok, err := e.Matches(labels)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// flowExpressionSelectors transforms expressions to ChaosMesh expressionSelectors in YAML flow format
+func flowExpressionSelectors(exprs []*LabelExpression) string {
+	if len(exprs) == 0 {
+		return ""
+	}
+	items := make([]string, 0)
+	for _, e := range exprs {
+		items = append(items, e.String())
+	}
+	return fmt.Sprintf("[%s]", strings.Join(items, ", "))
+}
+
+// componentLabelKeys returns keys pods are grouped by, composite keys take precedence over a single component key
+func (m *Controller) componentLabelKeys() []string {
+	if len(m.cfg.Havoc.ComponentLabelKeys) > 0 {
+		return m.cfg.Havoc.ComponentLabelKeys
+	}
+	return []string{m.cfg.Havoc.ComponentLabelKey}
+}
+
+// componentExpressionSelectors returns component label expressions in ChaosMesh format
+func (m *Controller) componentExpressionSelectors() string {
+	return flowExpressionSelectors(m.cfg.Havoc.ComponentLabelExpressions)
+}
+
+// componentSelector returns component group selector for pod labels, pod has no group if any key is missing
+// or labels don't satisfy component label expressions
+func (m *Controller) componentSelector(labels map[string]string) string {
+	if ok, _ := matchesExpressions(m.cfg.Havoc.ComponentLabelExpressions, labels); !ok {
+		return NoGroupKey
+	}
+	keys := m.componentLabelKeys()
+	if len(keys) == 1 {
+		return m.labelSelector(keys[0], labels[keys[0]])
+	}
+	group := make(map[string]string)
+	for _, k := range keys {
+		if labels[k] == "" {
+			return NoGroupKey
+		}
+		group[k] = labels[k]
+	}
+	return flowLabelSelector(group)
+}
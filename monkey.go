@@ -42,6 +42,10 @@ type Controller struct {
 	wg                *sync.WaitGroup
 	errors            []error
 	experimentActions []*ExperimentAction
+	// templates are user templates by template data type, experiments of other types are built as objects
+	templates map[string]string
+	// apply applies experiment and waits until it's finished
+	apply func(exp *NamedExperiment) error
 }
 
 func NewController(cfg *Config) (*Controller, error) {
@@ -50,17 +54,28 @@ func NewController(cfg *Config) (*Controller, error) {
 		cfg = DefaultConfig()
 		dumpConfig(cfg)
 	}
+	templates := make(map[string]string)
+	if cfg.Havoc.TemplatesDir != "" {
+		var err error
+		templates, err = LoadTemplates(cfg.Havoc.TemplatesDir)
+		if err != nil {
+			return nil, err
+		}
+	}
 	c := resty.New()
 	c.SetBaseURL(cfg.Havoc.Grafana.URL)
 	c.SetAuthScheme("Bearer")
 	c.SetAuthToken(cfg.Havoc.Grafana.Token)
-	return &Controller{
+	m := &Controller{
 		client:            c,
 		cfg:               cfg,
 		wg:                &sync.WaitGroup{},
 		errors:            make([]error, 0),
 		experimentActions: make([]*ExperimentAction, 0),
-	}, nil
+		templates:         templates,
+	}
+	m.apply = m.ApplyAndAnnotate
+	return m, nil
 }
 
 // AnnotateExperiment sends annotation marker to Grafana dashboard
@@ -101,6 +116,14 @@ func (m *Controller) AnnotateExperiment(a *ExperimentAction) error {
 }
 
 func (m *Controller) ApplyAndAnnotate(exp *NamedExperiment) error {
+	optedOut, err := m.ExperimentOptedOut(exp)
+	if err != nil {
+		return err
+	}
+	if optedOut {
+		L.Warn().Str("Name", exp.Name).Msg("Experiment targets opted out after it was generated, skipping")
+		return nil
+	}
 	ea := &ExperimentAction{
 		Name:           exp.Name,
 		ExperimentKind: exp.Kind,
@@ -122,7 +145,7 @@ func (m *Controller) Run() error {
 	}
 	m.ctx, m.cancel = context.WithTimeout(context.Background(), dur)
 	defer m.cancel()
-	existingExperimentTypes, err := m.readExistingExperimentTypes(m.cfg.Havoc.Dir)
+	allExperiments, err := m.ReadAllExperiments(m.cfg.Havoc.Dir)
 	if err != nil {
 		m.errors = append(m.errors, err)
 		return err
@@ -131,48 +154,14 @@ func (m *Controller) Run() error {
 	m.wg.Add(1)
 	switch m.cfg.Havoc.Monkey.Mode {
 	case MonkeyModeSeq:
-		for _, expType := range existingExperimentTypes {
-			experiments, err := m.ReadExperimentsFromDir([]string{expType}, m.cfg.Havoc.Dir)
-			if err != nil {
-				m.errors = append(m.errors, err)
-				return err
-			}
-			for _, exp := range experiments {
-				if err := m.ApplyAndAnnotate(exp); err != nil {
-					m.errors = append(m.errors, err)
-					return err
-				}
-				cdDuration, err := time.ParseDuration(m.cfg.Havoc.Monkey.Cooldown)
-				if err != nil {
-					m.errors = append(m.errors, err)
-					return err
-				}
-				select {
-				case <-m.ctx.Done():
-					m.wg.Done()
-					L.Info().Msg("Monkey has finished by timeout")
-					return nil
-				default:
-				}
-				L.Info().
-					Dur("Duration", cdDuration).
-					Msg("Cooldown between experiments")
-				time.Sleep(cdDuration)
-			}
+		cdDuration, err := time.ParseDuration(m.cfg.Havoc.Monkey.Cooldown)
+		if err != nil {
+			m.errors = append(m.errors, err)
+			return err
 		}
-		L.Info().Msg("Monkey has finished all scheduled experiments")
-		m.wg.Done()
+		return m.runSeq(allExperiments, cdDuration)
 	case MonkeyModeRandom:
-		allExperiments := make([]*NamedExperiment, 0)
 		r := rand.New(rand.NewSource(time.Now().Unix()))
-		for _, expType := range existingExperimentTypes {
-			experiments, err := m.ReadExperimentsFromDir([]string{expType}, m.cfg.Havoc.Dir)
-			if err != nil {
-				m.errors = append(m.errors, err)
-				return err
-			}
-			allExperiments = append(allExperiments, experiments...)
-		}
 		for {
 			select {
 			case <-m.ctx.Done():
@@ -181,7 +170,7 @@ func (m *Controller) Run() error {
 				return nil
 			default:
 				exp := pickExperiment(r, allExperiments)
-				if err := m.ApplyAndAnnotate(exp); err != nil {
+				if err := m.apply(exp); err != nil {
 					m.errors = append(m.errors, err)
 					return err
 				}
@@ -199,6 +188,30 @@ func (m *Controller) Run() error {
 	default:
 		return errors.New(ErrInvalidMode)
 	}
+}
+
+// runSeq applies experiments one by one, monkey duration is checked after every experiment before its cooldown,
+// newPlan marks experiments as skipped in the same order
+func (m *Controller) runSeq(experiments []*NamedExperiment, cooldown time.Duration) error {
+	for _, exp := range experiments {
+		if err := m.apply(exp); err != nil {
+			m.errors = append(m.errors, err)
+			return err
+		}
+		select {
+		case <-m.ctx.Done():
+			m.wg.Done()
+			L.Info().Msg("Monkey has finished by timeout")
+			return nil
+		default:
+		}
+		L.Info().
+			Dur("Duration", cooldown).
+			Msg("Cooldown between experiments")
+		time.Sleep(cooldown)
+	}
+	L.Info().Msg("Monkey has finished all scheduled experiments")
+	m.wg.Done()
 	return nil
 }
 
@@ -0,0 +1,235 @@
+package havoc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+const (
+	ErrNoNamespacesSelected = "no namespaces selected, pass them as arguments or set havoc.namespaces or havoc.namespace_selector in config"
+)
+
+// ResolveNamespaces returns namespaces from arguments, if empty from config list or config namespace label selector
+func (m *Controller) ResolveNamespaces(args []string) ([]string, error) {
+	if len(args) > 0 {
+		return args, nil
+	}
+	if len(m.cfg.Havoc.Namespaces) > 0 {
+		return m.cfg.Havoc.Namespaces, nil
+	}
+	if m.cfg.Havoc.NamespaceSelector != "" {
+		out, err := ExecCmd(fmt.Sprintf("kubectl get ns -l %s -o jsonpath={.items[*].metadata.name}", m.cfg.Havoc.NamespaceSelector))
+		if err != nil {
+			return nil, err
+		}
+		namespaces := strings.Fields(out)
+		if len(namespaces) > 0 {
+			sort.Strings(namespaces)
+			return namespaces, nil
+		}
+	}
+	return nil, errors.New(ErrNoNamespacesSelected)
+}
+
+// GenerateSpecsForNamespaces generates specs for multiple namespaces, should be used programmatically in tests,
+// for one namespace it's the same as GenerateSpecs, for multiple namespaces every namespace has its own subdirectory
+func (m *Controller) GenerateSpecsForNamespaces(namespaces []string) error {
+	if len(namespaces) == 1 {
+		return m.GenerateSpecs(namespaces[0])
+	}
+	podsInfo, err := m.getPodsInfoForNamespaces(namespaces)
+	if err != nil {
+		return err
+	}
+	_, err = m.generateSpecsForNamespaces(podsInfo)
+	return err
+}
+
+func (m *Controller) getPodsInfoForNamespaces(namespaces []string) (map[string]*PodsListResponse, error) {
+	podsInfo := make(map[string]*PodsListResponse)
+	for _, ns := range namespaces {
+		pi, err := m.GetPodsInfo(ns)
+		if err != nil {
+			return nil, err
+		}
+		podsInfo[ns] = pi
+	}
+	return podsInfo, nil
+}
+
+func (m *Controller) generateSpecsForNamespaces(podsInfo map[string]*PodsListResponse) (map[string]*ChaosSpecs, error) {
+	allSpecs, err := m.buildSpecsForNamespaces(podsInfo)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.RemoveAll(m.cfg.Havoc.Dir); err != nil {
+		return nil, err
+	}
+	if err := os.Mkdir(m.cfg.Havoc.Dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	for ns, csp := range allSpecs {
+		if err := csp.Dump(filepath.Join(m.cfg.Havoc.Dir, ns)); err != nil {
+			return nil, err
+		}
+	}
+	return allSpecs, nil
+}
+
+// buildSpecsForNamespaces generates specs for multiple namespaces without writing them
+func (m *Controller) buildSpecsForNamespaces(podsInfo map[string]*PodsListResponse) (map[string]*ChaosSpecs, error) {
+	namespaces := lo.Keys(podsInfo)
+	sort.Strings(namespaces)
+	allSpecs := make(map[string]*ChaosSpecs)
+	for _, ns := range namespaces {
+		L.Info().Str("Namespace", ns).Msg("Generating experiments for namespace")
+		csp, _, err := m.buildSpecs(ns, podsInfo[ns])
+		if err != nil {
+			return nil, errors.Wrap(err, ns)
+		}
+		allSpecs[ns] = csp
+	}
+	if m.hasCrossNamespacePartitions() {
+		for _, pair := range uniquePairs(namespaces) {
+			groupsFrom := m.networkPartitionGroups(podsInfo[pair[0]])
+			groupsTo := m.networkPartitionGroups(podsInfo[pair[1]])
+			experiments, ok := allSpecs[pair[0]].experiments(ChaosTypePartitionGroup)
+			if !ok || namespaceOptedOut(ChaosTypePartitionGroup, podsInfo[pair[1]].Namespace) {
+				continue
+			}
+			if m.cfg.Havoc.NetworkPartition.CrossNamespace {
+				if err := m.generateCrossNamespacePartitions(experiments, pair[0], pair[1], groupsFrom, groupsTo); err != nil {
+					return nil, err
+				}
+			}
+			if m.cfg.Havoc.NetworkPartition.NamespacePartition {
+				if err := m.generateNamespacePartition(experiments, pair[0], pair[1]); err != nil {
+					return nil, err
+				}
+			}
+		}
+		// cross namespace partitions are added after namespace specs were validated
+		for _, ns := range namespaces {
+			if err := allSpecs[ns].Validate(); err != nil {
+				return nil, errors.Wrap(err, ns)
+			}
+		}
+	}
+	return allSpecs, nil
+}
+
+func (m *Controller) hasCrossNamespacePartitions() bool {
+	return m.hasNetworkExperiments() &&
+		(m.cfg.Havoc.NetworkPartition.CrossNamespace || m.cfg.Havoc.NetworkPartition.NamespacePartition) &&
+		sliceContains(ChaosTypePartitionGroup, m.cfg.Havoc.ExperimentTypes)
+}
+
+// networkPartitionGroups returns sorted network partition label selectors of pods that are not ignored,
+// groups with pods opted out of partitions are skipped
+func (m *Controller) networkPartitionGroups(plr *PodsListResponse) []string {
+	key := m.cfg.Havoc.NetworkPartition.Label
+	optedOutGroups := m.optedOutNetworkGroups(plr.Items)
+	groups := make([]string, 0)
+	for _, p := range plr.Items {
+		if sliceContainsSubString(p.Metadata.Name, m.cfg.Havoc.IgnoredPods) {
+			continue
+		}
+		sel := m.labelSelector(key, p.Metadata.Labels[key])
+		if sel != NoGroupKey && !sliceContains(sel, groups) && !sliceContains(sel, optedOutGroups) {
+			groups = append(groups, sel)
+		}
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// generateCrossNamespacePartitions generates partitions between every network group of one namespace and every network group of another
+func (m *Controller) generateCrossNamespacePartitions(experiments *experimentSet, nsFrom string, nsTo string, groupsFrom []string, groupsTo []string) error {
+	for _, from := range groupsFrom {
+		for _, to := range groupsTo {
+			fromSide := partitionSide{Namespace: nsFrom, Name: fmt.Sprintf("%s-%s", nsFrom, from), Selector: from}
+			toSide := partitionSide{Namespace: nsTo, Name: fmt.Sprintf("%s-%s", nsTo, to), Selector: to}
+			if err := m.generatePartition(experiments, fromSide, toSide, m.partitionModes()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// experimentDirs returns dirs containing experiment type dirs, dir itself if experiments were generated for one namespace,
+// or a subdirectory for every namespace, subdirectories without experiment type dirs are not namespaces
+func experimentDirs(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	dirs := make([]string, 0)
+	hasTypes := false
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if sliceContains(e.Name(), KnownExperimentTypes) {
+			hasTypes = true
+			continue
+		}
+		nsDir := filepath.Join(dir, e.Name())
+		ok, err := hasExperimentTypeDirs(nsDir)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			dirs = append(dirs, nsDir)
+		}
+	}
+	if hasTypes {
+		dirs = append([]string{dir}, dirs...)
+	}
+	return dirs, nil
+}
+
+// hasExperimentTypeDirs checks if dir has at least one experiment type dir
+func hasExperimentTypeDirs(dir string) (bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false, err
+	}
+	for _, e := range entries {
+		if e.IsDir() && sliceContains(e.Name(), KnownExperimentTypes) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ReadAllExperiments reads experiments from all namespace dirs, ordered by experiment type lexicographically
+func (m *Controller) ReadAllExperiments(dir string) ([]*NamedExperiment, error) {
+	if err := readableExperimentsDir(dir); err != nil {
+		return nil, err
+	}
+	dirs, err := experimentDirs(dir)
+	if err != nil {
+		return nil, err
+	}
+	expTypes := append([]string{}, KnownExperimentTypes...)
+	sort.Strings(expTypes)
+	all := make([]*NamedExperiment, 0)
+	for _, expType := range expTypes {
+		for _, d := range dirs {
+			experiments, err := m.ReadExperimentsFromDir([]string{expType}, d)
+			if err != nil {
+				return nil, err
+			}
+			all = append(all, experiments...)
+		}
+	}
+	L.Info().Strs("Dirs", dirs).Int("Experiments", len(all)).Msg("Read experiments")
+	return all, nil
+}
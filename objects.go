@@ -0,0 +1,410 @@
+package havoc
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/chaos-mesh/chaos-mesh/api/v1alpha1"
+	"github.com/pkg/errors"
+	"github.com/smartcontractkit/havoc/k8schaos"
+	"gopkg.in/yaml.v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+// experimentObject is template data of an experiment that is a Chaos Mesh object
+type experimentObject interface {
+	experimentTemplate
+	Object() (client.Object, error)
+}
+
+// objectString builds experiment object and serializes it
+func objectString(data experimentObject) (string, error) {
+	obj, err := data.Object()
+	if err != nil {
+		return "", errors.Wrap(err, ErrInvalidExperiment)
+	}
+	manifest, err := k8schaos.EncodeObject(obj)
+	if err != nil {
+		return "", err
+	}
+	return string(manifest), nil
+}
+
+// buildExperiment builds experiment object and serializes it, if user template of data type is loaded
+// the experiment is rendered with it and decoded into its object instead
+func (m *Controller) buildExperiment(data experimentObject) (string, client.Object, error) {
+	typeName, _, ok := templateOverride(data, m.templates)
+	if !ok {
+		obj, err := data.Object()
+		if err != nil {
+			return "", nil, errors.Wrap(err, ErrInvalidExperiment)
+		}
+		manifest, err := k8schaos.EncodeObject(obj)
+		if err != nil {
+			return "", nil, err
+		}
+		return string(manifest), obj, nil
+	}
+	manifest, err := m.renderExperiment(data)
+	if err != nil {
+		return "", nil, err
+	}
+	obj, err := k8schaos.DecodeObject([]byte(manifest))
+	if err != nil {
+		return "", nil, errors.Wrap(err, fmt.Sprintf("%s: %s", ErrInvalidRenderedExperiment, typeName))
+	}
+	return manifest, obj, nil
+}
+
+// experimentSet is experiments of one type in ChaosSpecs, manifests by name and Chaos Mesh objects they are serialized from
+type experimentSet struct {
+	manifests map[string]string
+	objects   map[string]client.Object
+}
+
+// addType adds experiment type to specs, type is added even if no experiments are generated for it
+func (m *ChaosSpecs) addType(expType string) *experimentSet {
+	if m.ExperimentsByType == nil {
+		m.ExperimentsByType = make(map[string]map[string]string)
+	}
+	if m.ObjectsByType == nil {
+		m.ObjectsByType = make(map[string]map[string]client.Object)
+	}
+	if _, ok := m.ExperimentsByType[expType]; !ok {
+		m.ExperimentsByType[expType] = make(map[string]string)
+	}
+	if _, ok := m.ObjectsByType[expType]; !ok {
+		m.ObjectsByType[expType] = make(map[string]client.Object)
+	}
+	return &experimentSet{manifests: m.ExperimentsByType[expType], objects: m.ObjectsByType[expType]}
+}
+
+// experiments returns experiments of type, false if the type was not generated
+func (m *ChaosSpecs) experiments(expType string) (*experimentSet, bool) {
+	if _, ok := m.ExperimentsByType[expType]; !ok {
+		return nil, false
+	}
+	return m.addType(expType), true
+}
+
+// addExperiment builds experiment from template data and adds it with its manifest
+func (m *Controller) addExperiment(experiments *experimentSet, name string, data experimentObject) error {
+	manifest, obj, err := m.buildExperiment(data)
+	if err != nil {
+		return err
+	}
+	experiments.manifests[name] = manifest
+	experiments.objects[name] = obj
+	return nil
+}
+
+// labelSelectorSpec transforms template data label and expression selectors to Chaos Mesh selector
+func labelSelectorSpec(namespaces []string, selector string, exprs string) (v1alpha1.PodSelectorSpec, error) {
+	s := v1alpha1.PodSelectorSpec{}
+	s.Namespaces = namespaces
+	if selector != "" {
+		if err := yaml.Unmarshal([]byte(selector), &s.LabelSelectors); err != nil {
+			return s, errors.Wrap(err, fmt.Sprintf("invalid label selector: %s", selector))
+		}
+	}
+	if exprs != "" {
+		var reqs []metav1.LabelSelectorRequirement
+		if err := k8syaml.Unmarshal([]byte(exprs), &reqs); err != nil {
+			return s, errors.Wrap(err, fmt.Sprintf("invalid expression selectors: %s", exprs))
+		}
+		s.ExpressionSelectors = reqs
+	}
+	return s, nil
+}
+
+// podSelectorSpec transforms template data selectors to Chaos Mesh selector, pods are selected by name if there is no label selector
+func podSelectorSpec(namespaces []string, selector string, podName string, exprs string) (v1alpha1.PodSelectorSpec, error) {
+	s, err := labelSelectorSpec(namespaces, selector, exprs)
+	if err != nil {
+		return s, err
+	}
+	if selector == "" {
+		s.FieldSelectors = map[string]string{"metadata.name": podName}
+	}
+	return s, nil
+}
+
+// nodeSelectorSpec selects pods on nodes by names, or by node labels if node selector is set
+func nodeSelectorSpec(namespace string, nodes []string, nodeSelector string) (v1alpha1.PodSelectorSpec, error) {
+	s := v1alpha1.PodSelectorSpec{}
+	s.Namespaces = []string{namespace}
+	if nodeSelector == "" {
+		s.Nodes = nodes
+		return s, nil
+	}
+	if err := yaml.Unmarshal([]byte(nodeSelector), &s.NodeSelectors); err != nil {
+		return s, errors.Wrap(err, fmt.Sprintf("invalid node selector: %s", nodeSelector))
+	}
+	return s, nil
+}
+
+func parseDuration(d string) (time.Duration, error) {
+	if d == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(d)
+}
+
+// Object builds PodChaos object failing selected pods
+func (m PodFailureExperiment) Object() (client.Object, error) {
+	sel, err := podSelectorSpec(nil, m.Selector, m.PodName, m.ExpressionSelectors)
+	if err != nil {
+		return nil, err
+	}
+	d, err := parseDuration(m.Duration)
+	if err != nil {
+		return nil, err
+	}
+	spec := v1alpha1.PodChaosSpec{Action: v1alpha1.PodFailureAction}
+	spec.Mode = v1alpha1.SelectorMode(m.Mode)
+	spec.Value = m.ModeValue
+	spec.Selector = sel
+	return k8schaos.BuildPodChaos(k8schaos.PodChaosOpts{
+		Name:      m.ExperimentName,
+		Namespace: m.Namespace,
+		Duration:  d,
+		Spec:      spec,
+	})
+}
+
+// Object builds NetworkChaos object delaying traffic between selected pods
+func (m NetworkChaosExperiment) Object() (client.Object, error) {
+	sel, err := podSelectorSpec([]string{m.Namespace}, m.Selector, m.PodName, m.ExpressionSelectors)
+	if err != nil {
+		return nil, err
+	}
+	d, err := parseDuration(m.Duration)
+	if err != nil {
+		return nil, err
+	}
+	return k8schaos.BuildNetworkChaos(k8schaos.NetworkChaosOpts{
+		Name:      m.ExperimentName,
+		Namespace: m.Namespace,
+		Mode:      v1alpha1.SelectorMode(m.Mode),
+		ModeValue: m.ModeValue,
+		Duration:  d,
+		Selector:  sel,
+		Delay:     &v1alpha1.DelaySpec{Latency: m.Latency},
+		Direction: v1alpha1.From,
+		Target: &v1alpha1.PodSelector{
+			Selector: sel,
+			Mode:     v1alpha1.SelectorMode(m.Mode),
+			Value:    m.ModeValue,
+		},
+	})
+}
+
+// Object builds StressChaos object with CPU stressor
+func (m PodStressCPUExperiment) Object() (client.Object, error) {
+	sel, err := podSelectorSpec(nil, m.Selector, m.PodName, m.ExpressionSelectors)
+	if err != nil {
+		return nil, err
+	}
+	d, err := parseDuration(m.Duration)
+	if err != nil {
+		return nil, err
+	}
+	return k8schaos.BuildStressChaos(k8schaos.StressChaosOpts{
+		Name:           m.ExperimentName,
+		Namespace:      m.Namespace,
+		Mode:           v1alpha1.SelectorMode(m.Mode),
+		ModeValue:      m.ModeValue,
+		Duration:       d,
+		Selector:       sel,
+		ContainerNames: m.ContainerNames,
+		Stressors: &v1alpha1.Stressors{
+			CPUStressor: &v1alpha1.CPUStressor{
+				Stressor: v1alpha1.Stressor{Workers: m.Workers},
+				Load:     k8schaos.Ptr(m.Load),
+			},
+		},
+	})
+}
+
+// Object builds StressChaos object with memory stressor
+func (m PodStressMemoryExperiment) Object() (client.Object, error) {
+	sel, err := podSelectorSpec(nil, m.Selector, m.PodName, m.ExpressionSelectors)
+	if err != nil {
+		return nil, err
+	}
+	d, err := parseDuration(m.Duration)
+	if err != nil {
+		return nil, err
+	}
+	return k8schaos.BuildStressChaos(k8schaos.StressChaosOpts{
+		Name:           m.ExperimentName,
+		Namespace:      m.Namespace,
+		Mode:           v1alpha1.SelectorMode(m.Mode),
+		ModeValue:      m.ModeValue,
+		Duration:       d,
+		Selector:       sel,
+		ContainerNames: m.ContainerNames,
+		Stressors: &v1alpha1.Stressors{
+			MemoryStressor: &v1alpha1.MemoryStressor{
+				Stressor: v1alpha1.Stressor{Workers: m.Workers},
+				Size:     m.Memory,
+			},
+		},
+	})
+}
+
+// Object builds HTTPChaos object for requests of one path and method, it always targets requests
+func (m HTTPExperiment) Object() (client.Object, error) {
+	sel, err := podSelectorSpec([]string{m.Namespace}, m.Selector, m.PodName, m.ExpressionSelectors)
+	if err != nil {
+		return nil, err
+	}
+	d, err := parseDuration(m.Duration)
+	if err != nil {
+		return nil, err
+	}
+	return k8schaos.BuildHTTPChaos(k8schaos.HTTPChaosOpts{
+		Name:      m.ExperimentName,
+		Namespace: m.Namespace,
+		Mode:      v1alpha1.SelectorMode(m.Mode),
+		ModeValue: m.ModeValue,
+		Duration:  d,
+		Selector:  sel,
+		Target:    v1alpha1.PodHttpRequest,
+		Port:      int32(m.Port),
+		Path:      m.Path,
+		Method:    m.Method,
+		Abort:     m.Abort,
+	})
+}
+
+// Object builds NetworkChaos object partitioning pods selected by two label selectors
+func (m NetworkChaosGroupPartitionExperiment) Object() (client.Object, error) {
+	from, err := labelSelectorSpec([]string{m.Namespace}, m.SelectorFrom, "")
+	if err != nil {
+		return nil, err
+	}
+	targetNamespace := m.TargetNamespace
+	if targetNamespace == "" {
+		targetNamespace = m.Namespace
+	}
+	to, err := labelSelectorSpec([]string{targetNamespace}, m.SelectorTo, m.TargetExpressionSelectors)
+	if err != nil {
+		return nil, err
+	}
+	d, err := parseDuration(m.Duration)
+	if err != nil {
+		return nil, err
+	}
+	return k8schaos.BuildNetworkChaos(k8schaos.NetworkChaosOpts{
+		Name:      m.ExperimentName,
+		Namespace: m.Namespace,
+		Mode:      v1alpha1.SelectorMode(m.ModeFrom),
+		ModeValue: m.ModeFromValue,
+		Duration:  d,
+		Selector:  from,
+		Partition: true,
+		Direction: v1alpha1.Direction(m.Direction),
+		Target: &v1alpha1.PodSelector{
+			Selector: to,
+			Mode:     v1alpha1.SelectorMode(m.ModeTo),
+			Value:    m.ModeToValue,
+		},
+	})
+}
+
+// Object builds NetworkChaos object partitioning all namespace pods from an external URL
+func (m NetworkChaosExternalPartitionExperiment) Object() (client.Object, error) {
+	sel, err := labelSelectorSpec([]string{m.Namespace}, "", "")
+	if err != nil {
+		return nil, err
+	}
+	d, err := parseDuration(m.Duration)
+	if err != nil {
+		return nil, err
+	}
+	return k8schaos.BuildNetworkChaos(k8schaos.NetworkChaosOpts{
+		Name:      m.ExperimentName,
+		Namespace: m.Namespace,
+		Mode:      v1alpha1.AllMode,
+		Duration:  d,
+		Selector:  sel,
+		Partition: true,
+		Direction: v1alpha1.To,
+		Target: &v1alpha1.PodSelector{
+			Selector: sel,
+			Mode:     v1alpha1.AllMode,
+		},
+		ExternalTargets: []string{m.ExternalURL},
+	})
+}
+
+// Object builds PodChaos object killing selected containers
+func (m ContainerKillExperiment) Object() (client.Object, error) {
+	sel, err := podSelectorSpec(nil, m.Selector, m.PodName, m.ExpressionSelectors)
+	if err != nil {
+		return nil, err
+	}
+	spec := v1alpha1.PodChaosSpec{Action: v1alpha1.ContainerKillAction}
+	spec.Mode = v1alpha1.SelectorMode(m.Mode)
+	spec.Value = m.ModeValue
+	spec.Selector = sel
+	spec.ContainerNames = m.ContainerNames
+	return k8schaos.BuildPodChaos(k8schaos.PodChaosOpts{
+		Name:      m.ExperimentName,
+		Namespace: m.Namespace,
+		Spec:      spec,
+	})
+}
+
+// Object builds PodChaos object failing all namespace pods on nodes
+func (m TopologyFailureExperiment) Object() (client.Object, error) {
+	sel, err := nodeSelectorSpec(m.Namespace, m.Nodes, m.NodeSelector)
+	if err != nil {
+		return nil, err
+	}
+	d, err := parseDuration(m.Duration)
+	if err != nil {
+		return nil, err
+	}
+	spec := v1alpha1.PodChaosSpec{Action: v1alpha1.PodFailureAction}
+	spec.Mode = v1alpha1.AllMode
+	spec.Selector = sel
+	return k8schaos.BuildPodChaos(k8schaos.PodChaosOpts{
+		Name:      m.ExperimentName,
+		Namespace: m.Namespace,
+		Duration:  d,
+		Spec:      spec,
+	})
+}
+
+// Object builds NetworkChaos object partitioning namespace pods on nodes from pods on target nodes
+func (m TopologyPartitionExperiment) Object() (client.Object, error) {
+	sel, err := nodeSelectorSpec(m.Namespace, m.Nodes, m.NodeSelector)
+	if err != nil {
+		return nil, err
+	}
+	target, err := nodeSelectorSpec(m.Namespace, m.TargetNodes, "")
+	if err != nil {
+		return nil, err
+	}
+	d, err := parseDuration(m.Duration)
+	if err != nil {
+		return nil, err
+	}
+	return k8schaos.BuildNetworkChaos(k8schaos.NetworkChaosOpts{
+		Name:      m.ExperimentName,
+		Namespace: m.Namespace,
+		Mode:      v1alpha1.AllMode,
+		Duration:  d,
+		Selector:  sel,
+		Partition: true,
+		Direction: v1alpha1.Direction(m.Direction),
+		Target: &v1alpha1.PodSelector{
+			Selector: target,
+			Mode:     v1alpha1.AllMode,
+		},
+	})
+}
@@ -1,16 +1,22 @@
 package havoc
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/pkg/errors"
 	"github.com/samber/lo"
+	"os"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 )
 
 const (
-	ErrParsingOpenAPISpec = "failed to parse OpenAPISpec"
+	ErrParsingOpenAPISpec      = "failed to parse OpenAPISpec"
+	ErrOpenAPIPortNotResolved  = "OpenAPI spec port is not set or named container port is not found"
+	ErrInvalidOpenAPIReference = "invalid OpenAPI annotation, should be \"path:port\" or \"configmap:name/key:port\", port can be a container port name"
 )
 
 var (
@@ -18,6 +24,8 @@ var (
 )
 
 type OAPISpecData struct {
+	// Group is a component group value spec is mapped to
+	Group    string
 	Port     int64
 	RawPaths []string
 	SpecData map[string]*openapi3.PathItem
@@ -25,15 +33,29 @@ type OAPISpecData struct {
 
 // ParseOpenAPISpecs parses OpenAPI spec methods
 func (m *Controller) ParseOpenAPISpecs() ([]*OAPISpecData, error) {
+	return m.parseOpenAPISpecs(m.cfg.Havoc.OpenAPI.Mapping)
+}
+
+func (m *Controller) parseOpenAPISpecs(mapping map[string]*OpenApiSpecInfo) ([]*OAPISpecData, error) {
 	data := make([]*OAPISpecData, 0)
-	for _, oapiData := range m.cfg.Havoc.OpenAPI.Mapping {
+	for group, oapiData := range mapping {
 		for _, p := range oapiData.SpecToPortMappings {
 			loader := openapi3.NewLoader()
-			doc, err := loader.LoadFromFile(p.Path)
+			var doc *openapi3.T
+			var err error
+			if len(p.Data) > 0 {
+				doc, err = loader.LoadFromData(p.Data)
+			} else {
+				doc, err = loader.LoadFromFile(p.Path)
+			}
 			if err != nil {
 				return nil, errors.Wrap(err, ErrParsingOpenAPISpec)
 			}
+			if p.Port == 0 {
+				return nil, errors.Wrap(errors.New(ErrOpenAPIPortNotResolved), fmt.Sprintf("%s: %s", group, p.PortName))
+			}
 			oa := &OAPISpecData{
+				Group:    group,
 				Port:     p.Port,
 				RawPaths: make([]string, 0),
 				SpecData: doc.Paths.Map(),
@@ -49,8 +71,11 @@ func (m *Controller) ParseOpenAPISpecs() ([]*OAPISpecData, error) {
 }
 
 // generateOAPIExperiments generates HTTP experiments for a component group (entry), for each method type
-func (m *Controller) generateOAPIExperiments(experiments map[string]string, namespace string, entry lo.Entry[string, int], oapiSpecs []*OAPISpecData) error {
+func (m *Controller) generateOAPIExperiments(experiments *experimentSet, namespace string, entry lo.Entry[string, int], oapiSpecs []*OAPISpecData) error {
 	for _, apiSpec := range oapiSpecs {
+		if apiSpec.Group != m.groupValueFromLabelSelector(entry.Key) {
+			continue
+		}
 		for _, rawPath := range apiSpec.RawPaths {
 			pathData := apiSpec.SpecData[rawPath]
 			if pathData.Connect != nil {
@@ -104,7 +129,7 @@ func (m *Controller) generateOAPIExperiments(experiments map[string]string, name
 }
 
 func (m *Controller) generateHTTPExperiment(
-	experiments map[string]string,
+	experiments *experimentSet,
 	namespace string,
 	entry lo.Entry[string, int],
 	rawPath string,
@@ -114,22 +139,22 @@ func (m *Controller) generateHTTPExperiment(
 	sanitizedLabel := sanitizeLabel(entry.Key)
 	sanitizedRawPath := sanitizeLabel(rawPath)
 	sanitizedLabel = fmt.Sprintf("%s-%s-%s", sanitizedLabel, sanitizedRawPath, method)
-	experiment, err := HTTPExperiment{
-		Namespace:      namespace,
-		ExperimentName: strings.ToLower(fmt.Sprintf("%s-%s", ChaosTypeHTTP, sanitizedLabel)),
-		Duration:       m.cfg.Havoc.StressCPU.Duration,
-		Mode:           "all",
-		Selector:       entry.Key,
-		Target:         "Response",
-		Abort:          true,
-		Path:           pathToWildcardExpr(rawPath),
-		Method:         method,
-		Port:           port,
-	}.String()
+	err := m.addExperiment(experiments, sanitizedLabel, HTTPExperiment{
+		Namespace:           namespace,
+		ExperimentName:      strings.ToLower(fmt.Sprintf("%s-%s", ChaosTypeHTTP, sanitizedLabel)),
+		Duration:            m.cfg.Havoc.StressCPU.Duration,
+		Mode:                "all",
+		Selector:            entry.Key,
+		ExpressionSelectors: m.componentExpressionSelectors(),
+		Target:              "Response",
+		Abort:               true,
+		Path:                pathToWildcardExpr(rawPath),
+		Method:              method,
+		Port:                port,
+	})
 	if err != nil {
 		return err
 	}
-	experiments[sanitizedLabel] = experiment
 	return nil
 }
 
@@ -138,3 +163,139 @@ func (m *Controller) generateHTTPExperiment(
 func pathToWildcardExpr(path string) string {
 	return string(OpenAPIPathParam.ReplaceAll([]byte(path), []byte("*")))
 }
+
+// OpenAPIAnnotationKey is a pod annotation to discover OpenAPI specs of a component group,
+// value is a comma separated list of "path:port" or "configmap:name/key:port", port can be a container port name,
+// path is read locally if file exists, otherwise from the pod
+const (
+	OpenAPIAnnotationKey   = "havoc.io/openapi"
+	OpenAPIConfigMapPrefix = "configmap:"
+)
+
+// ConfigMapResponse ConfigMap info response from kubectl in JSON
+type ConfigMapResponse struct {
+	Data map[string]string `json:"data"`
+}
+
+// parseOpenAPIReference splits OpenAPI annotation entry into path and port
+func parseOpenAPIReference(ref string) (string, string, error) {
+	i := strings.LastIndex(ref, ":")
+	if i <= 0 || i == len(ref)-1 {
+		return "", "", errors.Wrap(errors.New(ErrInvalidOpenAPIReference), ref)
+	}
+	return ref[:i], ref[i+1:], nil
+}
+
+// containerPort returns port number by container port name of any of pods, 0 if it's not found
+func containerPort(name string, pods []*PodResponse) int64 {
+	for _, p := range pods {
+		for _, c := range p.Spec.Containers {
+			for _, port := range c.Ports {
+				if port.Name == name {
+					return port.ContainerPort
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// readOpenAPISpec reads spec from a ConfigMap, a local file, or a file inside the pod
+func (m *Controller) readOpenAPISpec(namespace string, podName string, path string) ([]byte, error) {
+	if strings.HasPrefix(path, OpenAPIConfigMapPrefix) {
+		name, key, ok := strings.Cut(strings.TrimPrefix(path, OpenAPIConfigMapPrefix), "/")
+		if !ok {
+			return nil, errors.Wrap(errors.New(ErrInvalidOpenAPIReference), path)
+		}
+		out, err := ExecCmd(fmt.Sprintf("kubectl get configmap %s -n %s -o json", name, namespace))
+		if err != nil {
+			return nil, err
+		}
+		var cm *ConfigMapResponse
+		if err := json.Unmarshal([]byte(out), &cm); err != nil {
+			return nil, err
+		}
+		data, ok := cm.Data[key]
+		if !ok {
+			return nil, errors.Wrap(errors.New(ErrInvalidOpenAPIReference), fmt.Sprintf("key %s not found in ConfigMap %s", key, name))
+		}
+		return []byte(data), nil
+	}
+	if _, err := os.Stat(path); err == nil {
+		return os.ReadFile(path)
+	}
+	out, err := ExecCmd(fmt.Sprintf("kubectl exec -n %s %s -- cat %s", namespace, podName, path))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(out), nil
+}
+
+// discoverOpenAPISpecs reads OpenAPI annotation of the first annotated pod in a component group
+func (m *Controller) discoverOpenAPISpecs(namespace string, pods []*PodResponse) (*OpenApiSpecInfo, error) {
+	for _, p := range pods {
+		refs, ok := p.Metadata.Annotations[OpenAPIAnnotationKey]
+		if !ok {
+			continue
+		}
+		info := &OpenApiSpecInfo{SpecToPortMappings: make([]*SpecToPort, 0)}
+		for _, ref := range strings.Split(refs, ",") {
+			path, port, err := parseOpenAPIReference(strings.TrimSpace(ref))
+			if err != nil {
+				return nil, err
+			}
+			data, err := m.readOpenAPISpec(namespace, p.Metadata.Name, path)
+			if err != nil {
+				return nil, errors.Wrap(err, ErrParsingOpenAPISpec)
+			}
+			stp := &SpecToPort{Path: path, Data: data}
+			if num, err := strconv.ParseInt(port, 10, 64); err == nil {
+				stp.Port = num
+			} else {
+				stp.PortName = port
+			}
+			info.SpecToPortMappings = append(info.SpecToPortMappings, stp)
+		}
+		return info, nil
+	}
+	return nil, nil
+}
+
+// openAPIMapping returns OpenAPI mapping of component groups HTTP experiments are generated for, config mapping
+// takes precedence over specs discovered from pod annotations, named ports are resolved from group pods
+func (m *Controller) openAPIMapping(namespace string, byComponent map[string][]*PodResponse, groupLabels []lo.Entry[string, int]) (map[string]*OpenApiSpecInfo, error) {
+	mapping := make(map[string]*OpenApiSpecInfo)
+	groups := lo.Map(groupLabels, func(e lo.Entry[string, int], _ int) string { return e.Key })
+	sort.Strings(groups)
+	for _, key := range groups {
+		if key == NoGroupKey || podsOptedOut(ChaosTypeHTTP, byComponent[key]...) {
+			continue
+		}
+		group := m.groupValueFromLabelSelector(key)
+		var info *OpenApiSpecInfo
+		if m.cfg.Havoc.OpenAPI != nil {
+			info = m.cfg.Havoc.OpenAPI.Mapping[group]
+		}
+		if info == nil {
+			discovered, err := m.discoverOpenAPISpecs(namespace, byComponent[key])
+			if err != nil {
+				return nil, errors.Wrap(err, group)
+			}
+			if discovered == nil {
+				continue
+			}
+			L.Info().Str("Group", group).Int("Specs", len(discovered.SpecToPortMappings)).Msg("Discovered OpenAPI specs")
+			info = discovered
+		}
+		resolved := &OpenApiSpecInfo{SpecToPortMappings: make([]*SpecToPort, 0)}
+		for _, stp := range info.SpecToPortMappings {
+			r := *stp
+			if r.Port == 0 && r.PortName != "" {
+				r.Port = containerPort(r.PortName, byComponent[key])
+			}
+			resolved.SpecToPortMappings = append(resolved.SpecToPortMappings, &r)
+		}
+		mapping[group] = resolved
+	}
+	return mapping, nil
+}
@@ -0,0 +1,152 @@
+package havoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// Pod and namespace labels or annotations to opt out of or into experiment types,
+// values are experiment types separated by "," or by "." for labels, ex.: havoc.io/ignore: "latency,failure",
+// "all" opts out of every experiment type
+const (
+	OptOutKey = "havoc.io/ignore"
+	OptInKey  = "havoc.io/only"
+
+	OptAllExperimentTypes = "all"
+)
+
+// NamespaceResponse namespace info response from kubectl in JSON
+type NamespaceResponse struct {
+	Metadata struct {
+		Name        string            `json:"name"`
+		Labels      map[string]string `json:"labels"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"metadata"`
+}
+
+// GetNamespaceInfo gets namespace labels and annotations
+func (m *Controller) GetNamespaceInfo(namespace string) (*NamespaceResponse, error) {
+	out, err := ExecCmd(fmt.Sprintf("kubectl get ns %s -o json", namespace))
+	if err != nil {
+		return nil, err
+	}
+	var nr *NamespaceResponse
+	if err := json.Unmarshal([]byte(out), &nr); err != nil {
+		return nil, err
+	}
+	return nr, nil
+}
+
+// optValues returns experiment types listed in label or annotation, annotation takes precedence
+func optValues(key string, labels map[string]string, annotations map[string]string) ([]string, bool) {
+	v, ok := annotations[key]
+	if !ok {
+		v, ok = labels[key]
+	}
+	if !ok {
+		return nil, false
+	}
+	values := strings.FieldsFunc(v, func(r rune) bool {
+		return r == ',' || r == '.' || r == ' '
+	})
+	return values, true
+}
+
+// optedOut checks if labels or annotations opt out of experiment type
+func optedOut(expType string, labels map[string]string, annotations map[string]string) bool {
+	if ignored, ok := optValues(OptOutKey, labels, annotations); ok {
+		if sliceContains(OptAllExperimentTypes, ignored) || sliceContains(expType, ignored) {
+			return true
+		}
+	}
+	if only, ok := optValues(OptInKey, labels, annotations); ok {
+		return !sliceContains(expType, only)
+	}
+	return false
+}
+
+// namespaceOptedOut checks if namespace opts out of experiment type, namespace is nil if it's unknown
+func namespaceOptedOut(expType string, ns *NamespaceResponse) bool {
+	return ns != nil && optedOut(expType, ns.Metadata.Labels, ns.Metadata.Annotations)
+}
+
+// podsOptedOut checks if any of pods opts out of experiment type
+func podsOptedOut(expType string, pods ...*PodResponse) bool {
+	return lo.SomeBy(pods, func(p *PodResponse) bool {
+		return optedOut(expType, p.Metadata.Labels, p.Metadata.Annotations)
+	})
+}
+
+// namespaceExperimentTypes returns configured experiment types namespace doesn't opt out of
+func (m *Controller) namespaceExperimentTypes(ns *NamespaceResponse) []string {
+	return lo.Reject(m.cfg.Havoc.ExperimentTypes, func(expType string, _ int) bool {
+		if namespaceOptedOut(expType, ns) {
+			L.Info().Str("Namespace", ns.Metadata.Name).Str("Type", expType).Msg("Namespace opted out of experiment type")
+			return true
+		}
+		return false
+	})
+}
+
+// optedOutNetworkGroups returns network group selectors having pods opted out of group partitions
+func (m *Controller) optedOutNetworkGroups(pods []*PodResponse) []string {
+	if !m.hasNetworkExperiments() {
+		return nil
+	}
+	key := m.cfg.Havoc.NetworkPartition.Label
+	groups := make([]string, 0)
+	for _, p := range pods {
+		sel := m.labelSelector(key, p.Metadata.Labels[key])
+		if sel != NoGroupKey && podsOptedOut(ChaosTypePartitionGroup, p) && !sliceContains(sel, groups) {
+			groups = append(groups, sel)
+		}
+	}
+	return groups
+}
+
+// experimentType returns experiment type from experiment dir name
+func experimentType(exp *NamedExperiment) string {
+	return filepath.Base(filepath.Dir(exp.Path))
+}
+
+// ExperimentOptedOut checks live pods and namespaces experiment selectors refer to,
+// returns true if any of them opted out of experiment type after experiment was generated
+func (m *Controller) ExperimentOptedOut(exp *NamedExperiment) (bool, error) {
+	if exp.Kind == ChaosTypeBlockchainSetHead {
+		return false, nil
+	}
+	expType := experimentType(exp)
+	namespaces, err := experimentNamespaces(exp)
+	if err != nil {
+		return false, err
+	}
+	for _, ns := range namespaces {
+		nsInfo, err := m.GetNamespaceInfo(ns)
+		if err != nil {
+			return false, err
+		}
+		if namespaceOptedOut(expType, nsInfo) {
+			L.Warn().Str("Namespace", ns).Str("Type", expType).Msg("Namespace opted out of experiment type")
+			return true, nil
+		}
+	}
+	selected, target, err := m.LiveExperimentTargets(exp)
+	if err != nil {
+		return false, err
+	}
+	candidates := selected.Candidates
+	if target != nil {
+		candidates = append(candidates, target.Candidates...)
+	}
+	for _, p := range candidates {
+		if podsOptedOut(expType, p) {
+			L.Warn().Str("Pod", p.Metadata.Name).Str("Type", expType).Msg("Pod opted out of experiment type")
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -9,9 +9,17 @@ import (
 )
 
 func ExecCmd(command string) (string, error) {
+	return execCmdInput(command, nil)
+}
+
+// execCmdInput executes command with input passed to its stdin, ex.: a manifest for "kubectl apply -f -"
+func execCmdInput(command string, input []byte) (string, error) {
 	L.Info().Interface("Command", command).Msg("Executing command")
 	c := strings.Split(command, " ")
 	cmd := exec.CommandContext(context.Background(), c[0], c[1:]...)
+	if input != nil {
+		cmd.Stdin = bytes.NewReader(input)
+	}
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
@@ -29,14 +29,48 @@ type ManifestPart struct {
 // PodsListResponse pod list response from kubectl in JSON
 type PodsListResponse struct {
 	Items []*PodResponse `json:"items"`
+	// Namespace is used to check namespace opt-out labels and annotations, nil if unknown
+	Namespace *NamespaceResponse `json:"-"`
+	// Workloads are used to select pods by their workload selector, nil if workload discovery is disabled
+	Workloads *WorkloadsListResponse `json:"-"`
 }
 
 // PodResponse pod info response from kubectl in JSON
 type PodResponse struct {
 	Metadata struct {
-		Name   string            `json:"name"`
-		Labels map[string]string `json:"labels"`
+		Name      string            `json:"name"`
+		Namespace string            `json:"namespace,omitempty"`
+		Labels    map[string]string `json:"labels"`
+		// Annotations are used to check opt-out, see OptOutKey
+		Annotations map[string]string `json:"annotations,omitempty"`
+		// OwnerReferences are used to find a workload pod belongs to
+		OwnerReferences []*OwnerReference `json:"ownerReferences,omitempty"`
 	} `json:"metadata"`
+	Spec struct {
+		NodeName   string               `json:"nodeName,omitempty"`
+		Containers []*ContainerResponse `json:"containers,omitempty"`
+	} `json:"spec"`
+	// NodeLabels are topology labels of the node pod is scheduled on, see attachNodeTopology
+	NodeLabels map[string]string `json:"-"`
+}
+
+// ContainerResponse pod container info from kubectl in JSON
+type ContainerResponse struct {
+	Name  string                   `json:"name"`
+	Ports []*ContainerPortResponse `json:"ports,omitempty"`
+}
+
+// ContainerPortResponse pod container port info from kubectl in JSON
+type ContainerPortResponse struct {
+	Name          string `json:"name"`
+	ContainerPort int64  `json:"containerPort"`
+}
+
+// OwnerReference pod owner reference from kubectl in JSON
+type OwnerReference struct {
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Controller bool   `json:"controller"`
 }
 
 type GroupInfo struct {
@@ -68,7 +102,10 @@ func (m *Controller) processPodInfoLo(plr *PodsListResponse) (map[string][]*PodR
 	filteredPods := lo.Filter(plr.Items, func(item *PodResponse, index int) bool {
 		return !sliceContainsSubString(item.Metadata.Name, m.cfg.Havoc.IgnoredPods)
 	})
-	labelsToAllow := append([]string{}, m.cfg.Havoc.ComponentLabelKey)
+	labelsToAllow := append([]string{OptOutKey, OptInKey}, m.componentLabelKeys()...)
+	for _, e := range m.cfg.Havoc.ComponentLabelExpressions {
+		labelsToAllow = append(labelsToAllow, e.Key)
+	}
 	if m.hasNetworkExperiments() {
 		labelsToAllow = append(labelsToAllow, m.cfg.Havoc.NetworkPartition.Label)
 	}
@@ -80,8 +117,7 @@ func (m *Controller) processPodInfoLo(plr *PodsListResponse) (map[string][]*PodR
 	}
 	// grouping
 	byComponent := lo.GroupBy(filteredPods, func(item *PodResponse) string {
-		key := m.cfg.Havoc.ComponentLabelKey
-		return m.labelSelector(key, item.Metadata.Labels[key])
+		return m.componentSelector(item.Metadata.Labels)
 	})
 	var byPartition map[string][]*PodResponse
 	if m.hasNetworkExperiments() {
@@ -133,15 +169,20 @@ func (m *Controller) labelSelector(k, v string) string {
 	}
 }
 
-// groupValueFromLabelSelector returns just the selector value
+// groupValueFromLabelSelector returns just the selector value, values of composite selectors are joined with "-"
 func (m *Controller) groupValueFromLabelSelector(selector string) string {
-	val := strings.Split(selector, ": ")[1]
-	return strings.ReplaceAll(val, "'", "")
+	selector = strings.TrimSuffix(strings.TrimPrefix(selector, "{"), "}")
+	values := make([]string, 0)
+	for _, pair := range strings.Split(selector, ", ") {
+		values = append(values, strings.ReplaceAll(strings.Split(pair, ": ")[1], "'", ""))
+	}
+	return strings.Join(values, "-")
 }
 
 // GetPodsInfo gets info about all the pods in the namespace
 func (m *Controller) GetPodsInfo(namespace string) (*PodsListResponse, error) {
-	if _, err := ExecCmd(fmt.Sprintf("kubectl get ns %s", namespace)); err != nil {
+	nsInfo, err := m.GetNamespaceInfo(namespace)
+	if err != nil {
 		return nil, errors.Wrap(errors.New(ErrNoNamespace), namespace)
 	}
 	var cmdBuilder strings.Builder
@@ -161,6 +202,13 @@ func (m *Controller) GetPodsInfo(namespace string) (*PodsListResponse, error) {
 	if err := json.Unmarshal([]byte(out), &pr); err != nil {
 		return nil, err
 	}
+	pr.Namespace = nsInfo
+	if m.cfg.Havoc.WorkloadDiscovery {
+		pr.Workloads, err = m.GetWorkloadsInfo(namespace)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return pr, nil
 }
 
@@ -0,0 +1,201 @@
+package havoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	ErrServiceHasNoSelector = "service has no selector, can't partition from it"
+)
+
+// Network partition directions
+const (
+	PartitionDirectionTo   = "to"
+	PartitionDirectionFrom = "from"
+	PartitionDirectionBoth = "both"
+)
+
+// partitionSide is one side of network partition, selector is empty if the whole namespace is selected
+type partitionSide struct {
+	Namespace           string
+	Name                string
+	Selector            string
+	ExpressionSelectors string
+	// AllMode selects all pods of this side instead of group modes, used for services, namespaces and "everyone else"
+	AllMode bool
+}
+
+type partitionMode struct {
+	Mode   string
+	Value  string
+	Suffix string
+}
+
+// partitionModes returns modes for group partitions from config
+func (m *Controller) partitionModes() []partitionMode {
+	modes := make([]partitionMode, 0)
+	for _, v := range m.cfg.Havoc.NetworkPartition.GroupPercentage {
+		modes = append(modes, partitionMode{Mode: SelectorModeFixedPercent, Value: v, Suffix: fmt.Sprintf("%s-perc", v)})
+	}
+	for _, v := range m.cfg.Havoc.NetworkPartition.GroupFixed {
+		modes = append(modes, partitionMode{Mode: SelectorModeFixed, Value: v, Suffix: fmt.Sprintf("%s-fixed", v)})
+	}
+	return modes
+}
+
+func (m *Controller) partitionDirection() string {
+	if m.cfg.Havoc.NetworkPartition == nil || m.cfg.Havoc.NetworkPartition.Direction == "" {
+		return PartitionDirectionFrom
+	}
+	return m.cfg.Havoc.NetworkPartition.Direction
+}
+
+// generatePartition generates partition experiments between two sides for every mode
+func (m *Controller) generatePartition(experiments *experimentSet, from partitionSide, to partitionSide, modes []partitionMode) error {
+	for _, mode := range modes {
+		label := sanitizeLabel(fmt.Sprintf("%s-to-%s", from.Name, to.Name))
+		if mode.Suffix != "" {
+			label = fmt.Sprintf("%s-%s", label, mode.Suffix)
+		}
+		e := NetworkChaosGroupPartitionExperiment{
+			Namespace:                 from.Namespace,
+			TargetNamespace:           to.Namespace,
+			ExperimentName:            fmt.Sprintf("%s-%s", ChaosTypePartitionGroup, label),
+			Duration:                  m.cfg.Havoc.NetworkPartition.Duration,
+			ModeFrom:                  mode.Mode,
+			ModeFromValue:             mode.Value,
+			ModeTo:                    mode.Mode,
+			ModeToValue:               mode.Value,
+			Direction:                 m.partitionDirection(),
+			SelectorFrom:              from.Selector,
+			SelectorTo:                to.Selector,
+			TargetExpressionSelectors: to.ExpressionSelectors,
+		}
+		if from.AllMode {
+			e.ModeFrom, e.ModeFromValue = SelectorModeAll, ""
+		}
+		if to.AllMode {
+			e.ModeTo, e.ModeToValue = SelectorModeAll, ""
+		}
+		if err := m.addExperiment(experiments, label, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flowLabelSelector transforms multiple labels to ChaosMesh labelSelectors in YAML flow format
+func flowLabelSelector(labels map[string]string) string {
+	pairs := make([]string, 0)
+	for k, v := range labels {
+		pairs = append(pairs, fmt.Sprintf("'%s': '%s'", k, v))
+	}
+	sort.Strings(pairs)
+	return fmt.Sprintf("{%s}", strings.Join(pairs, ", "))
+}
+
+// ServiceResponse service info response from kubectl in JSON
+type ServiceResponse struct {
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		Selector map[string]string `json:"selector"`
+	} `json:"spec"`
+}
+
+// GetServiceSelectors gets pod selectors of services from config in the namespace
+func (m *Controller) GetServiceSelectors(namespace string) (map[string]map[string]string, error) {
+	selectors := make(map[string]map[string]string)
+	if m.cfg.Havoc.NetworkPartition == nil {
+		return selectors, nil
+	}
+	for _, svc := range m.cfg.Havoc.NetworkPartition.Services {
+		out, err := ExecCmd(fmt.Sprintf("kubectl get svc -n %s %s -o json", namespace, svc))
+		if err != nil {
+			return nil, err
+		}
+		var sr *ServiceResponse
+		if err := json.Unmarshal([]byte(out), &sr); err != nil {
+			return nil, err
+		}
+		if len(sr.Spec.Selector) == 0 {
+			return nil, errors.Wrap(errors.New(ErrServiceHasNoSelector), svc)
+		}
+		selectors[svc] = sr.Spec.Selector
+	}
+	return selectors, nil
+}
+
+// generateServicePartitions generates partitions between every network group and every service
+func (m *Controller) generateServicePartitions(experiments *experimentSet, namespace string, groups []string, services map[string]map[string]string) error {
+	svcNames := make([]string, 0)
+	for svc := range services {
+		svcNames = append(svcNames, svc)
+	}
+	sort.Strings(svcNames)
+	for _, group := range groups {
+		for _, svc := range svcNames {
+			from := partitionSide{Namespace: namespace, Name: group, Selector: group}
+			to := partitionSide{Name: fmt.Sprintf("svc-%s", svc), Selector: flowLabelSelector(services[svc]), AllMode: true}
+			if err := m.generatePartition(experiments, from, to, m.partitionModes()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// generateIsolationPartitions generates one-to-many partitions, isolating every network group from all other pods in namespace
+func (m *Controller) generateIsolationPartitions(experiments *experimentSet, namespace string, groups []string) error {
+	key := m.cfg.Havoc.NetworkPartition.Label
+	for _, group := range groups {
+		from := partitionSide{Namespace: namespace, Name: group, Selector: group}
+		notInGroup := &LabelExpression{Key: key, Operator: LabelOperatorNotIn, Values: []string{m.groupValueFromLabelSelector(group)}}
+		to := partitionSide{
+			Name:                "all",
+			ExpressionSelectors: flowExpressionSelectors([]*LabelExpression{notInGroup}),
+			AllMode:             true,
+		}
+		if err := m.generatePartition(experiments, from, to, m.partitionModes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generateNamespacePartition generates partition between all pods of two namespaces
+func (m *Controller) generateNamespacePartition(experiments *experimentSet, nsFrom string, nsTo string) error {
+	from := partitionSide{Namespace: nsFrom, Name: fmt.Sprintf("ns-%s", nsFrom), AllMode: true}
+	to := partitionSide{Namespace: nsTo, Name: fmt.Sprintf("ns-%s", nsTo), AllMode: true}
+	return m.generatePartition(experiments, from, to, []partitionMode{{Mode: SelectorModeAll}})
+}
+
+// generateExtraPartitions generates service and isolation partitions for a namespace if they are enabled
+func (m *Controller) generateExtraPartitions(csp *ChaosSpecs, namespace string, plr *PodsListResponse) error {
+	experiments, ok := csp.experiments(ChaosTypePartitionGroup)
+	if !ok || !m.hasNetworkExperiments() {
+		return nil
+	}
+	groups := m.networkPartitionGroups(plr)
+	if len(m.cfg.Havoc.NetworkPartition.Services) > 0 {
+		services, err := m.GetServiceSelectors(namespace)
+		if err != nil {
+			return err
+		}
+		if err := m.generateServicePartitions(experiments, namespace, groups, services); err != nil {
+			return err
+		}
+	}
+	if m.cfg.Havoc.NetworkPartition.Isolate {
+		if err := m.generateIsolationPartitions(experiments, namespace, groups); err != nil {
+			return err
+		}
+	}
+	return nil
+}
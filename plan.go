@@ -0,0 +1,285 @@
+package havoc
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ErrExperimentDryRun   = "server-side dry-run of experiment manifest failed"
+	ErrExperimentDuration = "failed to parse experiment duration"
+)
+
+// ExperimentSelector is a subset of ChaosMesh selector that havoc generates
+type ExperimentSelector struct {
+	Namespaces          []string           `yaml:"namespaces"`
+	LabelSelectors      map[string]string  `yaml:"labelSelectors"`
+	ExpressionSelectors []*LabelExpression `yaml:"expressionSelectors"`
+	FieldSelectors      map[string]string  `yaml:"fieldSelectors"`
+	Nodes               []string           `yaml:"nodes"`
+	NodeSelectors       map[string]string  `yaml:"nodeSelectors"`
+}
+
+// ExperimentTarget is a selector with mode and value, the same way ChaosMesh defines it in spec and spec.target
+type ExperimentTarget struct {
+	Mode     string             `yaml:"mode"`
+	Value    string             `yaml:"value"`
+	Selector ExperimentSelector `yaml:"selector"`
+}
+
+type experimentSelectorsSpec struct {
+	Spec struct {
+		ExperimentTarget `yaml:",inline"`
+		Duration         string            `yaml:"duration"`
+		Target           *ExperimentTarget `yaml:"target"`
+	} `yaml:"spec"`
+}
+
+// PlannedExperiment is an experiment with its position in monkey run and pods it would affect
+type PlannedExperiment struct {
+	Order      int
+	Experiment *NamedExperiment
+	Duration   time.Duration
+	Cooldown   time.Duration
+	Targets    []string
+	// Skipped means experiment won't be reached because monkey duration is exceeded
+	Skipped bool
+}
+
+// ExperimentPlan describes what monkey or apply would do without injecting anything
+type ExperimentPlan struct {
+	Mode           string
+	MonkeyDuration time.Duration
+	Experiments    []*PlannedExperiment
+}
+
+// ExpectedDuration returns total duration of all experiments including cooldowns
+func (p *ExperimentPlan) ExpectedDuration() time.Duration {
+	var total time.Duration
+	for _, e := range p.Experiments {
+		total += e.Duration + e.Cooldown
+	}
+	return total
+}
+
+func (p *ExperimentPlan) String() string {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ORDER\tKIND\tNAME\tDURATION\tCOOLDOWN\tTARGETS")
+	for _, e := range p.Experiments {
+		order := fmt.Sprintf("%d", e.Order)
+		if e.Skipped {
+			order = fmt.Sprintf("%s (skipped)", order)
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			order,
+			e.Experiment.Kind,
+			e.Experiment.Name,
+			e.Duration,
+			e.Cooldown,
+			strings.Join(e.Targets, " "),
+		)
+	}
+	_ = w.Flush()
+	switch p.Mode {
+	case "":
+		sb.WriteString(fmt.Sprintf("Expected duration: %s\n", p.ExpectedDuration()))
+		return sb.String()
+	case MonkeyModeRandom:
+		sb.WriteString(fmt.Sprintf("Mode: %s, experiments are picked randomly from the list above until monkey duration %s is reached\n", p.Mode, p.MonkeyDuration))
+		return sb.String()
+	}
+	sb.WriteString(fmt.Sprintf("Mode: %s, expected duration: %s, monkey duration: %s\n", p.Mode, p.ExpectedDuration(), p.MonkeyDuration))
+	if p.MonkeyDuration != 0 && p.ExpectedDuration() > p.MonkeyDuration {
+		sb.WriteString("Monkey duration is less than expected duration, some experiments will be skipped\n")
+	}
+	return sb.String()
+}
+
+// parseExperimentSelectors parses selectors from experiment manifest, target selector is nil if experiment has no target
+func parseExperimentSelectors(exp *NamedExperiment) (*ExperimentTarget, *ExperimentTarget, string, error) {
+	var s *experimentSelectorsSpec
+	if err := yaml.Unmarshal(exp.CRDBytes, &s); err != nil {
+		return nil, nil, "", err
+	}
+	if len(s.Spec.Selector.Namespaces) == 0 && exp.Metadata.Namespace != "" {
+		s.Spec.Selector.Namespaces = []string{exp.Metadata.Namespace}
+	}
+	if s.Spec.Target != nil && len(s.Spec.Target.Selector.Namespaces) == 0 && exp.Metadata.Namespace != "" {
+		s.Spec.Target.Selector.Namespaces = []string{exp.Metadata.Namespace}
+	}
+	return &s.Spec.ExperimentTarget, s.Spec.Target, s.Spec.Duration, nil
+}
+
+// experimentDuration returns experiment duration, custom experiments has no duration
+func experimentDuration(exp *NamedExperiment) (time.Duration, error) {
+	if exp.Kind == ChaosTypeBlockchainSetHead {
+		return 0, nil
+	}
+	_, _, dur, err := parseExperimentSelectors(exp)
+	if err != nil {
+		return 0, err
+	}
+	if dur == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(dur)
+	if err != nil {
+		return 0, errors.Wrap(err, ErrExperimentDuration)
+	}
+	return d, nil
+}
+
+// newPlan creates an ordered plan for experiments, cooldown is applied after every experiment as monkey does.
+// Monkey checks its duration after an experiment and before its cooldown, so the last cooldown isn't counted when skipping
+func newPlan(mode string, monkeyDuration time.Duration, cooldown time.Duration, experiments []*NamedExperiment) (*ExperimentPlan, error) {
+	p := &ExperimentPlan{
+		Mode:           mode,
+		MonkeyDuration: monkeyDuration,
+		Experiments:    make([]*PlannedExperiment, 0),
+	}
+	var elapsed time.Duration
+	for i, exp := range experiments {
+		dur, err := experimentDuration(exp)
+		if err != nil {
+			return nil, errors.Wrap(err, exp.Path)
+		}
+		pe := &PlannedExperiment{
+			Order:      i + 1,
+			Experiment: exp,
+			Duration:   dur,
+			Cooldown:   cooldown,
+		}
+		if mode == MonkeyModeSeq && monkeyDuration != 0 && i > 0 && elapsed-cooldown >= monkeyDuration {
+			pe.Skipped = true
+		}
+		elapsed += dur + cooldown
+		p.Experiments = append(p.Experiments, pe)
+	}
+	return p, nil
+}
+
+// DryRunExperiment validates experiment with server-side dry-run and returns pods it would affect,
+// in "picked of candidates" format, target selector pods are prefixed with "->"
+func (m *Controller) DryRunExperiment(exp *NamedExperiment) ([]string, error) {
+	if exp.Kind == ChaosTypeBlockchainSetHead {
+		var rewind *BlockchainRewindHeadExperiment
+		if err := yaml.Unmarshal(exp.CRDBytes, &rewind); err != nil {
+			return nil, err
+		}
+		return []string{fmt.Sprintf("%s/%s", rewind.Namespace, rewind.PodName)}, nil
+	}
+	if _, err := execCmdInput("kubectl apply --dry-run=server -f -", exp.CRDBytes); err != nil {
+		return nil, errors.Wrap(err, ErrExperimentDryRun)
+	}
+	selected, target, err := m.LiveExperimentTargets(exp)
+	if err != nil {
+		return nil, err
+	}
+	targets := []string{fmt.Sprintf("%d of %s", selected.Picked, strings.Join(selected.Names(), ","))}
+	if target != nil {
+		targets = append(targets, fmt.Sprintf("->%d of %s", target.Picked, strings.Join(target.Names(), ",")))
+	}
+	return targets, nil
+}
+
+// planExperiments validates experiments, resolves their targets and returns a plan without injecting anything
+func (m *Controller) planExperiments(mode string, monkeyDuration time.Duration, cooldown time.Duration, experiments []*NamedExperiment) (*ExperimentPlan, error) {
+	p, err := newPlan(mode, monkeyDuration, cooldown, experiments)
+	if err != nil {
+		return nil, err
+	}
+	for _, pe := range p.Experiments {
+		targets, err := m.DryRunExperiment(pe.Experiment)
+		if err != nil {
+			return nil, errors.Wrap(err, pe.Experiment.Path)
+		}
+		pe.Targets = targets
+	}
+	return p, nil
+}
+
+// planMonkey plans experiments with monkey mode, duration and cooldown from config
+func (m *Controller) planMonkey(experiments []*NamedExperiment) (*ExperimentPlan, error) {
+	L.Info().Msg("Planning chaos monkey run")
+	dur, err := time.ParseDuration(m.cfg.Havoc.Monkey.Duration)
+	if err != nil {
+		return nil, err
+	}
+	cdDuration, err := time.ParseDuration(m.cfg.Havoc.Monkey.Cooldown)
+	if err != nil {
+		return nil, err
+	}
+	return m.planExperiments(m.cfg.Havoc.Monkey.Mode, dur, cdDuration, experiments)
+}
+
+// DryRun reads all experiments from dir in the same order monkey does and returns a plan
+func (m *Controller) DryRun() (*ExperimentPlan, error) {
+	experiments, err := m.ReadAllExperiments(m.cfg.Havoc.Dir)
+	if err != nil {
+		return nil, err
+	}
+	return m.planMonkey(experiments)
+}
+
+// DryRunNamespaces generates experiments for namespaces in memory and returns a plan of them, nothing is written to dir
+func (m *Controller) DryRunNamespaces(namespaces []string) (*ExperimentPlan, error) {
+	podsInfo, err := m.getPodsInfoForNamespaces(namespaces)
+	if err != nil {
+		return nil, err
+	}
+	allSpecs, err := m.buildSpecsForNamespaces(podsInfo)
+	if err != nil {
+		return nil, err
+	}
+	experiments, err := m.specsExperiments(allSpecs)
+	if err != nil {
+		return nil, err
+	}
+	return m.planMonkey(experiments)
+}
+
+// DryRunApply validates one experiment and returns a plan
+func (m *Controller) DryRunApply(exp *NamedExperiment) (*ExperimentPlan, error) {
+	return m.planExperiments("", 0, 0, []*NamedExperiment{exp})
+}
+
+// specsExperiments returns generated experiments in the same order ReadAllExperiments reads them once they are written,
+// paths are where experiments would be written
+func (m *Controller) specsExperiments(allSpecs map[string]*ChaosSpecs) ([]*NamedExperiment, error) {
+	namespaces := lo.Keys(allSpecs)
+	sort.Strings(namespaces)
+	expTypes := append([]string{}, KnownExperimentTypes...)
+	sort.Strings(expTypes)
+	all := make([]*NamedExperiment, 0)
+	for _, expType := range expTypes {
+		for _, ns := range namespaces {
+			dir := m.cfg.Havoc.Dir
+			if len(namespaces) > 1 {
+				dir = filepath.Join(dir, ns)
+			}
+			paths := make(map[string]string)
+			for expName, expBody := range allSpecs[ns].ExperimentsByType[expType] {
+				paths[filepath.Join(dir, expType, strings.ToLower(fmt.Sprintf("%s-%s.yaml", expType, expName)))] = expBody
+			}
+			sorted := lo.Keys(paths)
+			sort.Strings(sorted)
+			for _, p := range sorted {
+				exp, err := newNamedExperiment(p, []byte(paths[p]))
+				if err != nil {
+					return nil, err
+				}
+				all = append(all, exp)
+			}
+		}
+	}
+	return all, nil
+}
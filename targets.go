@@ -0,0 +1,239 @@
+package havoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	ErrUnsupportedFieldSelector = "unsupported field selector, only metadata.name and metadata.namespace are supported"
+	ErrInvalidModeValue         = "invalid selector mode value"
+	ErrUnknownSelectorMode      = "unknown selector mode"
+)
+
+// ChaosMesh selector modes
+const (
+	SelectorModeOne              = "one"
+	SelectorModeAll              = "all"
+	SelectorModeFixed            = "fixed"
+	SelectorModeFixedPercent     = "fixed-percent"
+	SelectorModeRandomMaxPercent = "random-max-percent"
+)
+
+// SelectedTargets is a result of selector evaluation against a pod list
+type SelectedTargets struct {
+	Mode       string
+	Value      string
+	Candidates []*PodResponse
+	// Picked is how many candidates ChaosMesh would pick, for random-max-percent it's the maximum
+	Picked int
+}
+
+func (s *SelectedTargets) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Mode: %s", s.Mode))
+	if s.Value != "" {
+		sb.WriteString(fmt.Sprintf(", value: %s", s.Value))
+	}
+	sb.WriteString(fmt.Sprintf(", candidates: %d, would pick: %d\n", len(s.Candidates), s.Picked))
+	for _, c := range s.Candidates {
+		sb.WriteString(fmt.Sprintf("  %s/%s\n", c.Metadata.Namespace, c.Metadata.Name))
+	}
+	return sb.String()
+}
+
+// Names returns candidates as namespace/name
+func (s *SelectedTargets) Names() []string {
+	names := make([]string, 0)
+	for _, c := range s.Candidates {
+		names = append(names, fmt.Sprintf("%s/%s", c.Metadata.Namespace, c.Metadata.Name))
+	}
+	return names
+}
+
+// SelectTargets evaluates selector with mode and value against a pod list the same way ChaosMesh does,
+// returns all candidate pods and how many of them would be picked
+func SelectTargets(t *ExperimentTarget, plr *PodsListResponse) (*SelectedTargets, error) {
+	candidates := make([]*PodResponse, 0)
+	for _, p := range plr.Items {
+		ok, err := matchesSelector(&t.Selector, p)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			candidates = append(candidates, p)
+		}
+	}
+	picked, err := pickedByMode(t.Mode, t.Value, len(candidates))
+	if err != nil {
+		return nil, err
+	}
+	return &SelectedTargets{
+		Mode:       t.Mode,
+		Value:      t.Value,
+		Candidates: candidates,
+		Picked:     picked,
+	}, nil
+}
+
+// matchesSelector checks if pod matches selector, pods dumped without namespace match any namespace
+func matchesSelector(sel *ExperimentSelector, p *PodResponse) (bool, error) {
+	if len(sel.Namespaces) > 0 && p.Metadata.Namespace != "" && !sliceContains(p.Metadata.Namespace, sel.Namespaces) {
+		return false, nil
+	}
+	for k, v := range sel.LabelSelectors {
+		if lv, ok := p.Metadata.Labels[k]; !ok || lv != v {
+			return false, nil
+		}
+	}
+	if ok, err := matchesExpressions(sel.ExpressionSelectors, p.Metadata.Labels); err != nil || !ok {
+		return false, err
+	}
+	if len(sel.Nodes) > 0 && !sliceContains(p.Spec.NodeName, sel.Nodes) {
+		return false, nil
+	}
+	for k, v := range sel.NodeSelectors {
+		if p.NodeLabels[k] != v {
+			return false, nil
+		}
+	}
+	for k, v := range sel.FieldSelectors {
+		switch k {
+		case "metadata.name":
+			if p.Metadata.Name != v {
+				return false, nil
+			}
+		case "metadata.namespace":
+			if p.Metadata.Namespace != v {
+				return false, nil
+			}
+		default:
+			return false, errors.Wrap(errors.New(ErrUnsupportedFieldSelector), k)
+		}
+	}
+	return true, nil
+}
+
+// pickedByMode calculates amount of pods picked by ChaosMesh mode
+func pickedByMode(mode string, value string, candidates int) (int, error) {
+	switch mode {
+	case SelectorModeOne:
+		return int(math.Min(1, float64(candidates))), nil
+	case SelectorModeAll, "":
+		return candidates, nil
+	case SelectorModeFixed:
+		num, err := strconv.Atoi(value)
+		if err != nil || num < 0 {
+			return 0, errors.Wrap(errors.New(ErrInvalidModeValue), value)
+		}
+		if num > candidates {
+			return candidates, nil
+		}
+		return num, nil
+	case SelectorModeFixedPercent, SelectorModeRandomMaxPercent:
+		perc, err := strconv.Atoi(value)
+		if err != nil || perc < 0 || perc > 100 {
+			return 0, errors.Wrap(errors.New(ErrInvalidModeValue), value)
+		}
+		return int(math.Floor(float64(candidates) * float64(perc) / 100)), nil
+	default:
+		return 0, errors.Wrap(errors.New(ErrUnknownSelectorMode), mode)
+	}
+}
+
+// ExperimentTargets evaluates experiment selector and target selector (if experiment has one) against a pod list
+func ExperimentTargets(exp *NamedExperiment, plr *PodsListResponse) (*SelectedTargets, *SelectedTargets, error) {
+	sel, target, _, err := parseExperimentSelectors(exp)
+	if err != nil {
+		return nil, nil, err
+	}
+	selected, err := SelectTargets(sel, plr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if target == nil {
+		return selected, nil, nil
+	}
+	selectedTarget, err := SelectTargets(target, plr)
+	if err != nil {
+		return nil, nil, err
+	}
+	return selected, selectedTarget, nil
+}
+
+// ReadPodsListResponse reads pods list from kubectl JSON output saved to a file
+func ReadPodsListResponse(path string) (*PodsListResponse, error) {
+	d, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var plr *PodsListResponse
+	if err := json.Unmarshal(d, &plr); err != nil {
+		return nil, err
+	}
+	return plr, nil
+}
+
+// GetPodsInNamespaces gets all the pods in namespaces without applying namespace label filter
+func (m *Controller) GetPodsInNamespaces(namespaces []string) (*PodsListResponse, error) {
+	all := &PodsListResponse{Items: make([]*PodResponse, 0)}
+	for _, ns := range namespaces {
+		out, err := ExecCmd(fmt.Sprintf("kubectl get pods -n %s -o json", ns))
+		if err != nil {
+			return nil, err
+		}
+		var pr *PodsListResponse
+		if err := json.Unmarshal([]byte(out), &pr); err != nil {
+			return nil, err
+		}
+		all.Items = append(all.Items, pr.Items...)
+	}
+	return all, nil
+}
+
+// experimentNamespaces returns all namespaces experiment selectors refer to
+func experimentNamespaces(exp *NamedExperiment) ([]string, error) {
+	sel, target, _, err := parseExperimentSelectors(exp)
+	if err != nil {
+		return nil, err
+	}
+	namespaces := append([]string{}, sel.Selector.Namespaces...)
+	if target != nil {
+		for _, ns := range target.Selector.Namespaces {
+			if !sliceContains(ns, namespaces) {
+				namespaces = append(namespaces, ns)
+			}
+		}
+	}
+	return namespaces, nil
+}
+
+// LiveExperimentTargets evaluates experiment selectors against pods currently running in the cluster
+func (m *Controller) LiveExperimentTargets(exp *NamedExperiment) (*SelectedTargets, *SelectedTargets, error) {
+	namespaces, err := experimentNamespaces(exp)
+	if err != nil {
+		return nil, nil, err
+	}
+	plr, err := m.GetPodsInNamespaces(namespaces)
+	if err != nil {
+		return nil, nil, err
+	}
+	sel, target, _, err := parseExperimentSelectors(exp)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(sel.Selector.NodeSelectors) > 0 || (target != nil && len(target.Selector.NodeSelectors) > 0) {
+		nlr, err := m.GetNodesInfo()
+		if err != nil {
+			return nil, nil, err
+		}
+		m.attachNodeTopology(plr, nlr)
+	}
+	return ExperimentTargets(exp, plr)
+}
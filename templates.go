@@ -0,0 +1,133 @@
+package havoc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ErrUnknownTemplate           = "unknown experiment template, file name must be a template data type name with .tmpl extension, ex.: PodFailureExperiment.tmpl"
+	ErrInvalidRenderedExperiment = "rendered experiment is not a valid Chaos Mesh object"
+)
+
+const (
+	TemplateExt         = ".tmpl"
+	ChaosMeshAPIVersion = "chaos-mesh.org/v1alpha1"
+)
+
+var (
+	// TemplateDataTypes are types passed to experiment templates, their exported fields are a stable contract for user templates:
+	// fields are only added, never renamed or removed, optional fields are empty when not used
+	TemplateDataTypes = []string{
+		reflect.TypeOf(PodFailureExperiment{}).Name(),
+		reflect.TypeOf(NetworkChaosExperiment{}).Name(),
+		reflect.TypeOf(NetworkChaosGroupPartitionExperiment{}).Name(),
+		reflect.TypeOf(NetworkChaosExternalPartitionExperiment{}).Name(),
+		reflect.TypeOf(PodStressCPUExperiment{}).Name(),
+		reflect.TypeOf(PodStressMemoryExperiment{}).Name(),
+		reflect.TypeOf(HTTPExperiment{}).Name(),
+		reflect.TypeOf(BlockchainRewindHeadExperiment{}).Name(),
+		reflect.TypeOf(ContainerKillExperiment{}).Name(),
+		reflect.TypeOf(TopologyFailureExperiment{}).Name(),
+		reflect.TypeOf(TopologyPartitionExperiment{}).Name(),
+	}
+)
+
+// LoadTemplates reads user templates from dir, every file is named after the template data type it renders
+func LoadTemplates(dir string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	templates := make(map[string]string)
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		typeName := strings.TrimSuffix(e.Name(), TemplateExt)
+		if filepath.Ext(e.Name()) != TemplateExt || !sliceContains(typeName, TemplateDataTypes) {
+			return nil, errors.Wrap(errors.New(ErrUnknownTemplate), e.Name())
+		}
+		d, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := template.New(typeName).Parse(string(d)); err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("%s: %s", ErrParsingTemplate, e.Name()))
+		}
+		L.Info().Str("Type", typeName).Str("Dir", dir).Msg("Using user template")
+		templates[typeName] = string(d)
+	}
+	return templates, nil
+}
+
+// templateOverride returns user template for template data type
+func templateOverride(jobSpec interface{}, overrides map[string]string) (string, string, bool) {
+	t := reflect.TypeOf(jobSpec)
+	if t == nil {
+		return "", "", false
+	}
+	tpl, ok := overrides[t.Name()]
+	return t.Name(), tpl, ok
+}
+
+// experimentTemplate is template data of an experiment rendered by its String method
+type experimentTemplate interface {
+	String() (string, error)
+}
+
+// renderExperiment renders experiment with user template of its data type if it's loaded, otherwise with its String method
+func (m *Controller) renderExperiment(data experimentTemplate) (string, error) {
+	typeName, tpl, ok := templateOverride(data, m.templates)
+	if !ok {
+		return data.String()
+	}
+	out, err := MarshalTemplate(data, typeName, tpl)
+	if err != nil {
+		return "", err
+	}
+	if err := validateRendered(typeName, out); err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+// validateRendered checks that experiment rendered from a user template is a Chaos Mesh object havoc can apply
+func validateRendered(typeName string, out string) error {
+	var crd CRD
+	if err := yaml.Unmarshal([]byte(out), &crd); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("%s: %s", ErrInvalidRenderedExperiment, typeName))
+	}
+	invalid := func(msg string) error {
+		return errors.Wrap(errors.New(ErrInvalidRenderedExperiment), fmt.Sprintf("%s: %s", typeName, msg))
+	}
+	if crd.Metadata.Name == "" {
+		return invalid("metadata.name is empty")
+	}
+	if typeName == reflect.TypeOf(BlockchainRewindHeadExperiment{}).Name() {
+		if crd.Kind != ChaosTypeBlockchainSetHead {
+			return invalid(fmt.Sprintf("kind must be %s", ChaosTypeBlockchainSetHead))
+		}
+		return nil
+	}
+	if crd.APIVersion != ChaosMeshAPIVersion {
+		return invalid(fmt.Sprintf("apiVersion must be %s", ChaosMeshAPIVersion))
+	}
+	if _, ok := ExperimentTypesToCRDNames[crd.Kind]; !ok {
+		return invalid(fmt.Sprintf("unknown kind %q", crd.Kind))
+	}
+	if crd.Metadata.Namespace == "" {
+		return invalid("metadata.namespace is empty")
+	}
+	if crd.Spec == nil {
+		return invalid("spec is empty")
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package havoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/samber/lo"
+)
+
+var (
+	// TopologyLabels are node labels recorded for every pod
+	TopologyLabels = []string{
+		"kubernetes.io/hostname",
+		"topology.kubernetes.io/region",
+		"topology.kubernetes.io/zone",
+	}
+	TopologyExperimentTypes = []string{
+		ChaosTypeNodeFailure,
+		ChaosTypeZoneFailure,
+		ChaosTypeNodePartition,
+		ChaosTypeZonePartition,
+	}
+)
+
+// NodesListResponse node list response from kubectl in JSON
+type NodesListResponse struct {
+	Items []*NodeResponse `json:"items"`
+}
+
+// NodeResponse node info response from kubectl in JSON
+type NodeResponse struct {
+	Metadata struct {
+		Name   string            `json:"name"`
+		Labels map[string]string `json:"labels"`
+	} `json:"metadata"`
+}
+
+// GetNodesInfo gets info about all the nodes in the cluster
+func (m *Controller) GetNodesInfo() (*NodesListResponse, error) {
+	out, err := ExecCmd("kubectl get nodes -o json")
+	if err != nil {
+		return nil, err
+	}
+	var nlr *NodesListResponse
+	if err := json.Unmarshal([]byte(out), &nlr); err != nil {
+		return nil, err
+	}
+	return nlr, nil
+}
+
+// attachNodeTopology records topology labels of the node every pod is scheduled on
+func (m *Controller) attachNodeTopology(plr *PodsListResponse, nlr *NodesListResponse) {
+	keys := append([]string{m.zoneLabel()}, TopologyLabels...)
+	byName := make(map[string]*NodeResponse)
+	for _, n := range nlr.Items {
+		byName[n.Metadata.Name] = n
+	}
+	for _, p := range plr.Items {
+		if n, ok := byName[p.Spec.NodeName]; ok {
+			p.NodeLabels = lo.PickByKeys(n.Metadata.Labels, keys)
+		}
+	}
+}
+
+func (m *Controller) zoneLabel() string {
+	if m.cfg.Havoc.Topology == nil || m.cfg.Havoc.Topology.ZoneLabel == "" {
+		return DefaultTopologyZoneLabel
+	}
+	return m.cfg.Havoc.Topology.ZoneLabel
+}
+
+func (m *Controller) hasTopologyExperiments() bool {
+	return m.cfg.Havoc.Topology != nil && len(lo.Intersect(m.cfg.Havoc.ExperimentTypes, TopologyExperimentTypes)) > 0
+}
+
+// podTopology returns sorted nodes and zones namespace pods are scheduled on, and nodes of every zone,
+// zones not listed in config are not eligible for zone experiments, but their nodes are still partition targets
+func (m *Controller) podTopology(plr *PodsListResponse) ([]string, []string, map[string][]string) {
+	nodes := make([]string, 0)
+	zones := make([]string, 0)
+	nodesByZone := make(map[string][]string)
+	for _, p := range plr.Items {
+		if p.Spec.NodeName == "" || sliceContainsSubString(p.Metadata.Name, m.cfg.Havoc.IgnoredPods) {
+			continue
+		}
+		if !sliceContains(p.Spec.NodeName, nodes) {
+			nodes = append(nodes, p.Spec.NodeName)
+		}
+		zone := p.NodeLabels[m.zoneLabel()]
+		if zone == "" {
+			continue
+		}
+		if !sliceContains(p.Spec.NodeName, nodesByZone[zone]) {
+			nodesByZone[zone] = append(nodesByZone[zone], p.Spec.NodeName)
+		}
+		eligible := len(m.cfg.Havoc.Topology.Zones) == 0 || sliceContains(zone, m.cfg.Havoc.Topology.Zones)
+		if eligible && !sliceContains(zone, zones) {
+			zones = append(zones, zone)
+		}
+	}
+	sort.Strings(nodes)
+	sort.Strings(zones)
+	for _, n := range nodesByZone {
+		sort.Strings(n)
+	}
+	return nodes, zones, nodesByZone
+}
+
+// podsOnNodes returns pods scheduled on nodes
+func podsOnNodes(plr *PodsListResponse, nodes ...string) []*PodResponse {
+	return lo.Filter(plr.Items, func(p *PodResponse, _ int) bool {
+		return sliceContains(p.Spec.NodeName, nodes)
+	})
+}
+
+// TopologyFailureExperiment is template data of node-failure and zone-failure experiments, Nodes is empty if NodeSelector is set
+type TopologyFailureExperiment struct {
+	ExperimentName string
+	Namespace      string
+	Duration       string
+	Nodes          []string
+	NodeSelector   string
+}
+
+func (m TopologyFailureExperiment) String() (string, error) {
+	return objectString(m)
+}
+
+// TopologyPartitionExperiment is template data of node-partition and zone-partition experiments
+type TopologyPartitionExperiment struct {
+	ExperimentName string
+	Namespace      string
+	Duration       string
+	Direction      string
+	Nodes          []string
+	NodeSelector   string
+	TargetNodes    []string
+}
+
+func (m TopologyPartitionExperiment) String() (string, error) {
+	return objectString(m)
+}
+
+// generateTopologyExperiments generates experiments failing or partitioning all namespace pods on one node or in one zone,
+// partitions target pods on all other nodes, so at least two nodes or zones are required
+func (m *Controller) generateTopologyExperiments(csp *ChaosSpecs, namespace string, plr *PodsListResponse, nlr *NodesListResponse) error {
+	m.attachNodeTopology(plr, nlr)
+	nodes, zones, nodesByZone := m.podTopology(plr)
+	L.Info().Strs("Nodes", nodes).Strs("Zones", zones).Msg("Topology found")
+	for _, expType := range TopologyExperimentTypes {
+		experiments, ok := csp.experiments(expType)
+		if !ok {
+			continue
+		}
+		switch expType {
+		case ChaosTypeNodeFailure:
+			for _, n := range nodes {
+				if podsOptedOut(expType, podsOnNodes(plr, n)...) {
+					continue
+				}
+				name := sanitizeLabel(n)
+				err := m.addExperiment(experiments, name, TopologyFailureExperiment{
+					ExperimentName: fmt.Sprintf("%s-%s", expType, name),
+					Namespace:      namespace,
+					Duration:       m.cfg.Havoc.Topology.Duration,
+					Nodes:          []string{n},
+				})
+				if err != nil {
+					return err
+				}
+			}
+		case ChaosTypeZoneFailure:
+			for _, z := range zones {
+				if podsOptedOut(expType, podsOnNodes(plr, nodesByZone[z]...)...) {
+					continue
+				}
+				name := sanitizeLabel(z)
+				err := m.addExperiment(experiments, name, TopologyFailureExperiment{
+					ExperimentName: fmt.Sprintf("%s-%s", expType, name),
+					Namespace:      namespace,
+					Duration:       m.cfg.Havoc.Topology.Duration,
+					NodeSelector:   flowLabelSelector(map[string]string{m.zoneLabel(): z}),
+				})
+				if err != nil {
+					return err
+				}
+			}
+		case ChaosTypeNodePartition:
+			for _, n := range nodes {
+				others := lo.Without(nodes, n)
+				if len(others) == 0 || podsOptedOut(expType, podsOnNodes(plr, nodes...)...) {
+					continue
+				}
+				name := sanitizeLabel(n)
+				err := m.addExperiment(experiments, name, TopologyPartitionExperiment{
+					ExperimentName: fmt.Sprintf("%s-%s", expType, name),
+					Namespace:      namespace,
+					Duration:       m.cfg.Havoc.Topology.Duration,
+					Direction:      m.partitionDirection(),
+					Nodes:          []string{n},
+					TargetNodes:    others,
+				})
+				if err != nil {
+					return err
+				}
+			}
+		case ChaosTypeZonePartition:
+			for _, z := range zones {
+				others := lo.Without(nodes, nodesByZone[z]...)
+				if len(others) == 0 || podsOptedOut(expType, podsOnNodes(plr, nodes...)...) {
+					continue
+				}
+				name := sanitizeLabel(z)
+				err := m.addExperiment(experiments, name, TopologyPartitionExperiment{
+					ExperimentName: fmt.Sprintf("%s-%s", expType, name),
+					Namespace:      namespace,
+					Duration:       m.cfg.Havoc.Topology.Duration,
+					Direction:      m.partitionDirection(),
+					NodeSelector:   flowLabelSelector(map[string]string{m.zoneLabel(): z}),
+					TargetNodes:    others,
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package havoc
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/chaos-mesh/chaos-mesh/api/v1alpha1"
+	"github.com/pkg/errors"
+	"github.com/smartcontractkit/havoc/k8schaos"
+	"gopkg.in/yaml.v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	ErrInvalidExperiment = "generated experiment is not a valid Chaos Mesh object"
+)
+
+// chaosValidator is implemented by all Chaos Mesh kinds, it runs the same checks as Chaos Mesh validating webhook
+type chaosValidator interface {
+	Validate() error
+}
+
+// chaosDefaulter is implemented by all Chaos Mesh kinds, it runs the same defaulting as Chaos Mesh mutating webhook
+type chaosDefaulter interface {
+	Default()
+}
+
+// ValidateExperiment decodes experiment manifest into its Chaos Mesh type strictly, applies defaults
+// and runs Chaos Mesh validation locally, custom kinds are not validated
+func ValidateExperiment(manifest string) error {
+	_, err := decodeExperiment(manifest)
+	return err
+}
+
+// decodeExperiment decodes and validates experiment manifest, returns nil object for custom kinds
+func decodeExperiment(manifest string) (client.Object, error) {
+	var crd CRD
+	if err := yaml.Unmarshal([]byte(manifest), &crd); err != nil {
+		return nil, errors.Wrap(err, ErrInvalidExperiment)
+	}
+	if crd.Kind == ChaosTypeBlockchainSetHead {
+		return nil, nil
+	}
+	obj, err := k8schaos.DecodeObject([]byte(manifest))
+	if err != nil {
+		return nil, errors.Wrap(err, ErrInvalidExperiment)
+	}
+	if err := validateObject(obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// validateObject runs Chaos Mesh defaulting and validation of an object
+func validateObject(obj client.Object) error {
+	// defaults are applied to a copy, so objects stay the same as manifests
+	checked := obj.DeepCopyObject()
+	kind := checked.GetObjectKind().GroupVersionKind().Kind
+	if d, ok := checked.(chaosDefaulter); ok {
+		d.Default()
+	}
+	if v, ok := checked.(chaosValidator); ok {
+		if err := v.Validate(); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("%s: %s %s", ErrInvalidExperiment, kind, obj.GetName()))
+		}
+	}
+	if s, ok := checked.(v1alpha1.InnerObjectWithSelector); ok {
+		if err := validateSelectors(s.GetSelectorSpecs()); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("%s: %s %s", ErrInvalidExperiment, kind, obj.GetName()))
+		}
+	}
+	return nil
+}
+
+// validateSelectors checks selector modes and label selectors, they are validated by CRD schema and at injection time
+// in Chaos Mesh, so its webhook validation skips them
+func validateSelectors(selectors map[string]interface{}) error {
+	paths := make([]string, 0)
+	for path := range selectors {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		var ps *v1alpha1.PodSelector
+		switch s := selectors[path].(type) {
+		case *v1alpha1.PodSelector:
+			ps = s
+		case *v1alpha1.ContainerSelector:
+			ps = &s.PodSelector
+		default:
+			continue
+		}
+		switch ps.Mode {
+		case v1alpha1.OneMode, v1alpha1.AllMode, v1alpha1.FixedMode, v1alpha1.FixedPercentMode, v1alpha1.RandomMaxPercentMode:
+		default:
+			return errors.Errorf("%s.mode: unsupported mode %q, only one, all, fixed, fixed-percent and random-max-percent are supported", path, ps.Mode)
+		}
+		_, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{
+			MatchLabels:      ps.Selector.LabelSelectors,
+			MatchExpressions: ps.Selector.ExpressionSelectors,
+		})
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("%s.selector", path))
+		}
+	}
+	return nil
+}
+
+// Validate validates all experiments, objects experiments were built from are validated as is, other manifests
+// are decoded into ObjectsByType, returns the first invalid experiment error in type and name order
+func (m *ChaosSpecs) Validate() error {
+	expTypes := make([]string, 0)
+	for expType := range m.ExperimentsByType {
+		expTypes = append(expTypes, expType)
+	}
+	sort.Strings(expTypes)
+	objects := make(map[string]map[string]client.Object)
+	for _, expType := range expTypes {
+		names := make([]string, 0)
+		for name := range m.ExperimentsByType[expType] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		objects[expType] = make(map[string]client.Object)
+		for _, name := range names {
+			obj, ok := m.ObjectsByType[expType][name]
+			var err error
+			if ok {
+				err = validateObject(obj)
+			} else {
+				obj, err = decodeExperiment(m.ExperimentsByType[expType][name])
+			}
+			if err != nil {
+				return errors.Wrap(err, fmt.Sprintf("%s/%s", expType, name))
+			}
+			if obj != nil {
+				objects[expType][name] = obj
+			}
+		}
+	}
+	m.ObjectsByType = objects
+	return nil
+}
@@ -0,0 +1,177 @@
+package havoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// Workload kinds that own pods
+const (
+	WorkloadKindDeployment  = "Deployment"
+	WorkloadKindStatefulSet = "StatefulSet"
+	WorkloadKindDaemonSet   = "DaemonSet"
+	WorkloadKindReplicaSet  = "ReplicaSet"
+)
+
+// Workload is a group of pods owned by one Deployment, StatefulSet or DaemonSet
+type Workload struct {
+	Kind string
+	Name string
+	// Selector is the workload pod selector, it survives rollouts, unlike pod names and pod template labels
+	Selector map[string]string
+	Pods     []*PodResponse
+}
+
+// WorkloadsListResponse workloads list response from kubectl in JSON
+type WorkloadsListResponse struct {
+	Items []*WorkloadResponse `json:"items"`
+}
+
+// WorkloadResponse Deployment, StatefulSet, DaemonSet or ReplicaSet info response from kubectl in JSON
+type WorkloadResponse struct {
+	Kind     string `json:"kind"`
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		Selector struct {
+			MatchLabels map[string]string `json:"matchLabels"`
+		} `json:"selector"`
+	} `json:"spec"`
+}
+
+// GetWorkloadsInfo gets workloads that can own pods in the namespace
+func (m *Controller) GetWorkloadsInfo(namespace string) (*WorkloadsListResponse, error) {
+	out, err := ExecCmd(fmt.Sprintf("kubectl get deployments,statefulsets,daemonsets,replicasets -n %s -o json", namespace))
+	if err != nil {
+		return nil, err
+	}
+	var wlr *WorkloadsListResponse
+	if err := json.Unmarshal([]byte(out), &wlr); err != nil {
+		return nil, err
+	}
+	return wlr, nil
+}
+
+// podWorkload resolves pod controller owner to a workload, ReplicaSets are resolved to Deployments using pod-template-hash
+func podWorkload(p *PodResponse) (string, string, bool) {
+	for _, o := range p.Metadata.OwnerReferences {
+		if !o.Controller {
+			continue
+		}
+		switch o.Kind {
+		case WorkloadKindStatefulSet, WorkloadKindDaemonSet:
+			return o.Kind, o.Name, true
+		case WorkloadKindReplicaSet:
+			hash := p.Metadata.Labels["pod-template-hash"]
+			if hash != "" && strings.HasSuffix(o.Name, "-"+hash) {
+				return WorkloadKindDeployment, strings.TrimSuffix(o.Name, "-"+hash), true
+			}
+			return o.Kind, o.Name, true
+		}
+	}
+	return "", "", false
+}
+
+// workloadSelectors returns match labels of workload selectors by kind and name,
+// pod template labels can change between revisions, selector of the workload matches pods of all revisions
+func workloadSelectors(wlr *WorkloadsListResponse) map[string]map[string]string {
+	selectors := make(map[string]map[string]string)
+	if wlr == nil {
+		return selectors
+	}
+	for _, w := range wlr.Items {
+		selectors[fmt.Sprintf("%s/%s", w.Kind, w.Metadata.Name)] = w.Spec.Selector.MatchLabels
+	}
+	return selectors
+}
+
+// discoverWorkloads groups pods without a component label by the workload that owns them,
+// pods with a component label are already targeted by component group experiments
+func (m *Controller) discoverWorkloads(plr *PodsListResponse) []*Workload {
+	selectors := workloadSelectors(plr.Workloads)
+	byWorkload := make(map[string]*Workload)
+	for _, p := range plr.Items {
+		if sliceContainsSubString(p.Metadata.Name, m.cfg.Havoc.IgnoredPods) {
+			continue
+		}
+		if m.componentSelector(p.Metadata.Labels) != NoGroupKey {
+			continue
+		}
+		kind, name, ok := podWorkload(p)
+		if !ok {
+			continue
+		}
+		key := fmt.Sprintf("%s/%s", kind, name)
+		if _, ok := byWorkload[key]; !ok {
+			byWorkload[key] = &Workload{Kind: kind, Name: name}
+		}
+		byWorkload[key].Pods = append(byWorkload[key].Pods, p)
+	}
+	workloads := make([]*Workload, 0)
+	for key, w := range byWorkload {
+		w.Selector = selectors[key]
+		if len(w.Selector) == 0 {
+			L.Warn().Str("Kind", w.Kind).Str("Name", w.Name).Msg("Workload is not found or has no match labels in its selector, falling back to pod names")
+			continue
+		}
+		workloads = append(workloads, w)
+	}
+	sort.Slice(workloads, func(i, j int) bool {
+		return workloads[i].Name < workloads[j].Name
+	})
+	for _, w := range workloads {
+		L.Info().
+			Str("Kind", w.Kind).
+			Str("Name", w.Name).
+			Int("Pods", len(w.Pods)).
+			Interface("Selector", w.Selector).
+			Msg("Workload found")
+	}
+	return workloads
+}
+
+// podsWithoutWorkload filters out pods that belong to discovered workloads
+func podsWithoutWorkload(pods []*PodResponse, workloads []*Workload) []*PodResponse {
+	owned := make(map[string]bool)
+	for _, w := range workloads {
+		for _, p := range w.Pods {
+			owned[p.Metadata.Name] = true
+		}
+	}
+	return lo.Reject(pods, func(item *PodResponse, _ int) bool {
+		return owned[item.Metadata.Name]
+	})
+}
+
+// generateWorkloadExperiments generates single pod experiments for every workload, selecting one of workload pods by stable selector
+func (m *Controller) generateWorkloadExperiments(csp *ChaosSpecs, namespace string, workloads []*Workload) error {
+	for _, expType := range []string{ChaosTypeFailure, ChaosTypeLatency, ChaosTypeStressCPU, ChaosTypeStressMemory, ChaosTypeContainerKill} {
+		experiments, ok := csp.experiments(expType)
+		if !ok {
+			continue
+		}
+		for _, w := range workloads {
+			if podsOptedOut(expType, w.Pods...) {
+				continue
+			}
+			containers, ok := m.podContainers(expType, w.Pods...)
+			if !ok {
+				continue
+			}
+			name := strings.ToLower(fmt.Sprintf("%s-%s", w.Kind, w.Name))
+			experiment, err := m.singleExperiment(expType, namespace, name, "", flowLabelSelector(w.Selector), containers)
+			if err != nil {
+				return err
+			}
+			if err := m.addExperiment(experiments, name, experiment); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}